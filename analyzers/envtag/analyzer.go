@@ -0,0 +1,191 @@
+package envtag
+
+import (
+	"errors"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const (
+	name = "envtag"
+	doc  = `check struct tags read by the sethvargo parser
+
+The analyzer reports unknown tag options, malformed or missing names, prefixes
+on fields that are not structs, defaults on required fields, duplicate names
+within a struct and tags on unexported fields that the collector skips.`
+
+	defaultTagKey = "env"
+)
+
+type (
+	Config struct {
+		TagKey string
+	}
+
+	checker struct {
+		pass   *analysis.Pass
+		config *Config
+	}
+)
+
+func New() *analysis.Analyzer {
+	return NewWithConfig(Config{TagKey: defaultTagKey})
+}
+
+func NewWithConfig(config Config) *analysis.Analyzer {
+	if config.TagKey == "" {
+		config.TagKey = defaultTagKey
+	}
+
+	analyzer := &analysis.Analyzer{ //nolint:exhaustruct // too many options
+		Name:     name,
+		Doc:      doc,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Run: func(pass *analysis.Pass) (any, error) {
+			return run(pass, &config)
+		},
+	}
+
+	analyzer.Flags.StringVar(&config.TagKey, "tag", config.TagKey, "struct tag key to check")
+
+	return analyzer
+}
+
+func run(pass *analysis.Pass, config *Config) (any, error) {
+	inspection, _ := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	check := &checker{pass: pass, config: config}
+
+	inspection.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(node ast.Node) {
+		if rStruct, ok := node.(*ast.StructType); ok {
+			check.structType(rStruct)
+		}
+	})
+
+	return nil, nil //nolint:nilnil // the analyzer has no result
+}
+
+func (c *checker) structType(rStruct *ast.StructType) {
+	seen := make(map[string]string)
+
+	for _, field := range rStruct.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+
+		tags, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+
+		tagVal := reflect.StructTag(tags).Get(c.config.TagKey)
+		if tagVal == "" || tagVal == "-" {
+			continue
+		}
+
+		for _, fieldName := range names(field) {
+			c.field(field, fieldName, tagVal, seen)
+		}
+	}
+}
+
+func (c *checker) field(field *ast.Field, fieldName string, tagVal string, seen map[string]string) {
+	if !ast.IsExported(fieldName) {
+		c.pass.Reportf(field.Tag.Pos(), "tag on unexported field %s is skipped", fieldName)
+
+		return
+	}
+
+	value, prefix, tag, err := sethvargo.ParseTag(tagVal)
+
+	switch {
+	case errors.Is(err, sethvargo.ErrInvalidName):
+		c.pass.Reportf(field.Tag.Pos(), "malformed name %q", value)
+	case err != nil:
+		c.pass.Reportf(field.Tag.Pos(), "%s", err)
+	}
+
+	nested := walkable(c.pass.TypesInfo.TypeOf(field.Type))
+
+	if prefix != "" && !nested {
+		c.pass.Reportf(field.Tag.Pos(), "prefix %q on non-struct field %s", prefix, fieldName)
+	}
+
+	if value == "" && !nested {
+		c.pass.Reportf(field.Tag.Pos(), "missing name on non-struct field %s", fieldName)
+	}
+
+	if tag.Required && tag.Default != "" {
+		c.pass.Reportf(field.Tag.Pos(), "default on required field %s is never used", fieldName)
+	}
+
+	if value == "" {
+		return
+	}
+
+	if other, ok := seen[value]; ok {
+		c.pass.Reportf(field.Tag.Pos(), "duplicate name %q, already used by %s", value, other)
+	}
+
+	seen[value] = fieldName
+}
+
+func names(field *ast.Field) []string {
+	if len(field.Names) != 0 {
+		names := make([]string, 0, len(field.Names))
+		for _, ident := range field.Names {
+			names = append(names, ident.Name)
+		}
+
+		return names
+	}
+
+	// embedded field is named by its type
+	expr := field.Type
+
+	for {
+		switch typ := expr.(type) {
+		case *ast.StarExpr:
+			expr = typ.X
+		case *ast.SelectorExpr:
+			return []string{typ.Sel.Name}
+		case *ast.IndexExpr:
+			expr = typ.X
+		case *ast.IndexListExpr:
+			expr = typ.X
+		case *ast.Ident:
+			return []string{typ.Name}
+		default:
+			return nil
+		}
+	}
+}
+
+// walkable reports whether the collector may descend into the field, so
+// the prefix makes sense there.
+func walkable(rType types.Type) bool {
+	if rType == nil {
+		return true
+	}
+
+	switch typ := rType.Underlying().(type) {
+	case *types.Pointer:
+		return walkable(typ.Elem())
+	case *types.Slice:
+		return walkable(typ.Elem())
+	case *types.Array:
+		return walkable(typ.Elem())
+	case *types.Map:
+		return walkable(typ.Elem())
+	case *types.Struct, *types.Interface:
+		return true
+	default:
+		return false
+	}
+}
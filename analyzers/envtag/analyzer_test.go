@@ -0,0 +1,36 @@
+package envtag_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/analyzers/envtag"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj := envtag.New()
+
+	require.NoError(t, analysis.Validate([]*analysis.Analyzer{obj}))
+	assert.Equal(t, "env", obj.Flags.Lookup("tag").Value.String())
+}
+
+func TestAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	analysistest.Run(t, analysistest.TestData(), envtag.New(), "a")
+}
+
+func TestAnalyzerCustomTag(t *testing.T) {
+	t.Parallel()
+
+	obj := envtag.NewWithConfig(envtag.Config{TagKey: "custom"})
+
+	require.NoError(t, obj.Flags.Set("tag", "custom"))
+
+	analysistest.Run(t, analysistest.TestData(), obj, "custom")
+}
@@ -0,0 +1,34 @@
+package a
+
+import (
+	"time"
+)
+
+type Database struct {
+	Host string `env:"HOST"`
+}
+
+type Config struct {
+	Database
+
+	Valid          string         `env:"VALID,required"`
+	Nested         Database       `env:",prefix=DB_"`
+	Pointer        *Database      `env:",prefix=PTR_"`
+	Slice          []Database     `env:",prefix=SLICE_"`
+	Plugins        map[string]any `env:",prefix=PLUGIN_"`
+	Skipped        string         `env:"-"`
+	Other          string         `json:"other"`
+	Typo           string         `env:"TYPO,requierd"`           // want `unknown option: requierd`
+	Spaced         string         `env:"SPACED,required "`        // want `unknown option: required `
+	Lower          string         `env:"db_host, prefix=X"`       // want `prefix "X" on non-struct field Lower`
+	Malformed      string         `env:"MY-VAR"`                  // want `malformed name "MY-VAR"`
+	Digit          string         `env:"1VAR"`                    // want `malformed name "1VAR"`
+	NoName         string         `env:",required"`               // want `missing name on non-struct field NoName`
+	Both           string         `env:"BOTH,required,default=x"` // want `default on required field Both is never used`
+	First          string         `env:"SAME"`
+	Second         int            `env:"SAME"`              // want `duplicate name "SAME", already used by First`
+	Timeout        time.Duration  `env:"TIMEOUT,prefix=T_"` // want `prefix "T_" on non-struct field Timeout`
+	A, B           string         `env:"PAIR"`              // want `duplicate name "PAIR", already used by A`
+	unexported     string         `env:"UNEXPORTED"`        // want `tag on unexported field unexported is skipped`
+	*time.Location `env:",prefix=LOC_"`
+}
@@ -0,0 +1,6 @@
+package custom
+
+type Config struct {
+	Value string `custom:"VALUE,requierd"` // want `unknown option: requierd`
+	Other string `env:"OTHER,requierd"`
+}
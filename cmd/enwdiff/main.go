@@ -0,0 +1,105 @@
+// Command enwdiff compares the environment variables of two versions of a
+// configuration struct and prints the changes, e.g. for a PR comment.
+//
+// Usage:
+//
+//	git worktree add /tmp/base main
+//	enwdiff -type Config -format markdown /tmp/base/config ./config
+//
+// Each argument is either a directory with the package that declares the
+// type, or a JSON manifest with the `[]*enw.Env` collected before.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/diff"
+	"github.com/therenotomorrow/enw/internal/static"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultTagKey = "env"
+	inputs        = 2
+
+	ErrMissingInputs ex.Const = "missing inputs, want old and new"
+	ErrMissingType   ex.Const = "missing type"
+	ErrReadManifest  ex.Const = "cannot read manifest"
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "name of the configuration struct, required for directories")
+		tagKey   = flag.String("tag", defaultTagKey, "struct tag key to read")
+		format   = flag.String("format", string(diff.FormatText), "output format: text, markdown or json")
+	)
+
+	flag.Parse()
+
+	err := run(os.Stdout, flag.Args(), *typeName, *tagKey, diff.Format(*format))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "enwdiff:", err)
+		os.Exit(1)
+	}
+}
+
+func run(w io.Writer, args []string, typeName string, tagKey string, format diff.Format) error {
+	if len(args) != inputs {
+		return ErrMissingInputs
+	}
+
+	before, err := inventory(args[0], typeName, tagKey)
+	if err != nil {
+		return err
+	}
+
+	after, err := inventory(args[1], typeName, tagKey)
+	if err != nil {
+		return err
+	}
+
+	return diff.Inventories(before, after).Render(w, format)
+}
+
+func inventory(path string, typeName string, tagKey string) ([]*enw.Env, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, ErrReadManifest.Because(err)
+	}
+
+	if !info.IsDir() {
+		return manifest(path)
+	}
+
+	if typeName == "" {
+		return nil, ErrMissingType
+	}
+
+	_, obj, err := static.Load(path, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return static.Manifest(obj, tagKey), nil
+}
+
+func manifest(path string) ([]*enw.Env, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, ErrReadManifest.Because(err)
+	}
+
+	envs := make([]*enw.Env, 0)
+
+	err = json.Unmarshal(data, &envs)
+	if err != nil {
+		return nil, ErrReadManifest.Because(err)
+	}
+
+	return envs, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/diff"
+	"github.com/therenotomorrow/enw/internal/static"
+)
+
+const (
+	oldDir = "testdata/old"
+	newDir = "testdata/new"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := run(&buf, []string{oldDir, newDir}, "Config", "env", diff.FormatText)
+	want := `DB_HOST (required toggled) at Config->Database->Host: "false" -> "true"
+DB_PORT (default changed) at Config->Database->Port: "5432" -> "6432"
+DEBUG (removed) at Config->Debug
+LEGACY_MODE (renamed) at Config->Legacy: "LEGACY" -> "LEGACY_MODE"
+TIMEOUT (default changed) at Config->Timeout: "10" -> "10s"
+TIMEOUT (type changed) at Config->Timeout: "int" -> "time.Duration"
+TOKEN (path moved) at Config->Auth->Token: "Config->Token" -> "Config->Auth->Token"
+TOKEN (required toggled) at Config->Auth->Token: "false" -> "true"
+VERBOSE (added) at Config->Verbose
+WORKERS (added) at Config->Workers
+`
+
+	require.NoError(t, err)
+	assert.Equal(t, want, buf.String())
+}
+
+func TestRunManifest(t *testing.T) {
+	t.Parallel()
+
+	envs := []*enw.Env{{Var: "DEBUG", Path: "Config->Verbose", Type: "bool"}}
+	data, err := json.Marshal(envs)
+
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	var buf bytes.Buffer
+
+	err = run(&buf, []string{path, newDir}, "Config", "env", diff.FormatMarkdown)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "| `VERBOSE` | renamed | `Config->Verbose` | `DEBUG` -> `VERBOSE` |\n")
+}
+
+func TestRunFailure(t *testing.T) {
+	t.Parallel()
+
+	broken := filepath.Join(t.TempDir(), "broken.json")
+
+	require.NoError(t, os.WriteFile(broken, []byte("{"), 0o600))
+
+	tests := []struct {
+		err      error
+		name     string
+		typeName string
+		args     []string
+		format   diff.Format
+	}{
+		{
+			name:     "missing inputs",
+			args:     []string{oldDir},
+			typeName: "Config",
+			format:   diff.FormatText,
+			err:      ErrMissingInputs,
+		},
+		{
+			name:     "missing type",
+			args:     []string{oldDir, newDir},
+			typeName: "",
+			format:   diff.FormatText,
+			err:      ErrMissingType,
+		},
+		{
+			name:     "missing file",
+			args:     []string{"missing.json", newDir},
+			typeName: "Config",
+			format:   diff.FormatText,
+			err:      ErrReadManifest,
+		},
+		{
+			name:     "broken manifest",
+			args:     []string{oldDir, broken},
+			typeName: "Config",
+			format:   diff.FormatText,
+			err:      ErrReadManifest,
+		},
+		{
+			name:     "type not found",
+			args:     []string{oldDir, newDir},
+			typeName: "Missing",
+			format:   diff.FormatText,
+			err:      static.ErrTypeNotFound,
+		},
+		{
+			name:     "invalid format",
+			args:     []string{oldDir, newDir},
+			typeName: "Config",
+			format:   "yaml",
+			err:      diff.ErrInvalidFormat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := run(new(bytes.Buffer), test.args, test.typeName, "env", test.format)
+
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
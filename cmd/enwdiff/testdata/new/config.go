@@ -0,0 +1,23 @@
+package config
+
+import (
+	"time"
+)
+
+type Database struct {
+	Host string `env:"HOST,required"`
+	Port int    `env:"PORT,default=6432"`
+}
+
+type Config struct {
+	Database Database      `env:",prefix=DB_"`
+	Verbose  bool          `env:"VERBOSE"`
+	Timeout  time.Duration `env:"TIMEOUT,default=10s"`
+	Auth     Auth
+	Workers  int    `env:"WORKERS"`
+	Legacy   string `env:"LEGACY_MODE"`
+}
+
+type Auth struct {
+	Token string `env:"TOKEN,required"`
+}
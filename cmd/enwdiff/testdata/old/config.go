@@ -0,0 +1,14 @@
+package config
+
+type Database struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT,default=5432"`
+}
+
+type Config struct {
+	Database Database `env:",prefix=DB_"`
+	Debug    bool     `env:"DEBUG"`
+	Timeout  int      `env:"TIMEOUT,default=10"`
+	Token    string   `env:"TOKEN"`
+	Legacy   string   `env:"LEGACY"`
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/internal/static"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	enwPath = "github.com/therenotomorrow/enw"
+
+	ErrMissingType  ex.Const = "missing type"
+	ErrLoadPackage           = static.ErrLoadPackage
+	ErrTypeNotFound          = static.ErrTypeNotFound
+	ErrNotStruct             = static.ErrNotStruct
+)
+
+type (
+	helper struct {
+		rType types.Type
+		name  string
+	}
+
+	generator struct {
+		pkg     *types.Package
+		imports map[string]string
+		tagKey  string
+		helpers []*helper
+		body    bytes.Buffer
+		indexes bool
+		keys    bool
+	}
+)
+
+func Generate(dir string, typeName string, tagKey string) ([]byte, error) {
+	pkg, obj, err := static.Load(dir, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &generator{
+		pkg:     pkg,
+		imports: map[string]string{"cmp": "cmp", "context": "context", "errors": "errors", "slices": "slices"},
+		tagKey:  cmp.Or(tagKey, defaultTagKey),
+		helpers: make([]*helper, 0),
+		body:    bytes.Buffer{},
+		indexes: false,
+		keys:    false,
+	}
+
+	return gen.generate(obj)
+}
+
+func (g *generator) generate(obj *types.TypeName) ([]byte, error) {
+	name := obj.Name()
+	manifest := static.Manifest(obj, g.tagKey)
+
+	root := g.helper(obj.Type())
+
+	// helpers are appended while the previous ones are generated
+	for i := 0; i < len(g.helpers); i++ {
+		g.collector(g.helpers[i])
+	}
+
+	g.imports[enwPath] = "enw"
+
+	var out bytes.Buffer
+
+	out.WriteString("// Code generated by enwgen. DO NOT EDIT.\n\npackage " + g.pkg.Name() + "\n\n")
+	g.writeImports(&out)
+
+	fmt.Fprintf(&out, "var %sManifest = []*enw.Env{\n", name)
+
+	for _, env := range manifest {
+		fmt.Fprintf(&out, "{%s},\n", envFields(env, strconv.Quote(env.Var), strconv.Quote(env.Path)))
+	}
+
+	fmt.Fprintf(&out, "}\n\n")
+	fmt.Fprintf(&out, `func Collect%[1]s(target *%[1]s) ([]*enw.Env, error) {
+	if target == nil {
+		return nil, enw.ErrNilTarget
+	}
+
+	envs := %[2]s(make([]*enw.Env, 0), map[any]bool{target: true}, target, "", %[3]q)
+
+	slices.SortStableFunc(envs, func(a, b *enw.Env) int {
+		return cmp.Compare(a.Var, b.Var)
+	})
+
+	return envs, nil
+}
+
+func Load%[1]s(ctx context.Context, finder *enw.Finder, target *%[1]s) ([]*enw.Env, error) {
+	envs, err := Collect%[1]s(target)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, env := range envs {
+		found, err := finder.FindContext(ctx, env)
+
+		switch {
+		case errors.Is(err, enw.ErrEnvNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			envs[i] = found
+		}
+	}
+
+	return envs, nil
+}
+
+`, name, root, name)
+
+	out.Write(g.body.Bytes())
+
+	if g.keys {
+		out.WriteString(sortedKeys)
+	}
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, ex.Unexpected(err)
+	}
+
+	return code, nil
+}
+
+func (g *generator) writeImports(out *bytes.Buffer) {
+	if g.indexes {
+		g.imports["strconv"] = "strconv"
+	}
+
+	if g.keys {
+		g.imports["fmt"] = "fmt"
+	}
+
+	// standard packages go first, the same as `gci` sorts them
+	std, other := make([]string, 0), make([]string, 0)
+
+	for path := range g.imports {
+		if strings.Contains(path, ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+
+	slices.Sort(std)
+	slices.Sort(other)
+
+	out.WriteString("import (\n")
+
+	for _, path := range append(append(std, ""), other...) {
+		if path == "" {
+			out.WriteString("\n")
+
+			continue
+		}
+
+		if name := g.imports[path]; name != path[strings.LastIndex(path, "/")+1:] {
+			out.WriteString(name + " ")
+		}
+
+		out.WriteString(strconv.Quote(path) + "\n")
+	}
+
+	out.WriteString(")\n\n")
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg.Path() == g.pkg.Path() {
+		return ""
+	}
+
+	if name, ok := g.imports[pkg.Path()]; ok {
+		return name
+	}
+
+	name := pkg.Name()
+
+	for _, used := range g.imports {
+		if used == name {
+			name += strconv.Itoa(len(g.imports))
+
+			break
+		}
+	}
+
+	g.imports[pkg.Path()] = name
+
+	return name
+}
+
+func (g *generator) helper(rType types.Type) string {
+	for _, known := range g.helpers {
+		if types.Identical(known.rType, rType) {
+			return known.name
+		}
+	}
+
+	name := "enwCollectStruct"
+	if named, ok := types.Unalias(rType).(*types.Named); ok {
+		name = "enwCollect" + strings.ToUpper(named.Obj().Name()[:1]) + named.Obj().Name()[1:]
+	}
+
+	name += strconv.Itoa(len(g.helpers))
+
+	g.helpers = append(g.helpers, &helper{rType: rType, name: name})
+
+	return name
+}
+
+// collector generates the same walk as `Collector.Collect` does for the values.
+func (g *generator) collector(help *helper) {
+	rStruct, _ := help.rType.Underlying().(*types.Struct)
+
+	fmt.Fprintf(&g.body,
+		"func %s(envs []*enw.Env, visiting map[any]bool, value *%s, prefix string, path string) []*enw.Env {\n",
+		help.name, types.TypeString(help.rType, g.qualifier),
+	)
+
+	for i := range rStruct.NumFields() {
+		field := rStruct.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		fieldPath := "path + " + strconv.Quote("->"+field.Name())
+
+		env, nested := static.Parse(rStruct, i, g.tagKey)
+		if env != nil {
+			env.Package = static.PkgPath(help.rType)
+
+			fmt.Fprintf(&g.body, "envs = append(envs, &enw.Env{%s})\n",
+				envFields(env, "prefix + "+strconv.Quote(env.Var), fieldPath),
+			)
+		}
+
+		nestedPrefix := "prefix"
+		if nested != "" {
+			nestedPrefix += " + " + strconv.Quote(nested)
+		}
+
+		g.descend(field.Type(), "value."+field.Name(), nestedPrefix, fieldPath)
+	}
+
+	g.body.WriteString("return envs\n}\n\n")
+}
+
+func walkable(rType types.Type) bool {
+	rStruct, ok := rType.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+
+	for i := range rStruct.NumFields() {
+		if rStruct.Field(i).Exported() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *generator) descend(rType types.Type, expr string, prefix string, path string) {
+	switch typ := rType.Underlying().(type) {
+	case *types.Slice:
+		g.descendIndexes(typ.Elem(), expr, prefix, path)
+	case *types.Array:
+		g.descendIndexes(typ.Elem(), expr, prefix, path)
+	case *types.Map:
+		g.descendKeys(typ.Elem(), expr, prefix, path)
+	case *types.Struct:
+		if walkable(rType) {
+			fmt.Fprintf(&g.body, "envs = %s(envs, visiting, &%s, %s, %s)\n", g.helper(rType), expr, prefix, path)
+		}
+	case *types.Pointer:
+		g.descendPointer(typ, expr, prefix, path)
+	}
+}
+
+func (g *generator) descendIndexes(elem types.Type, expr string, prefix string, path string) {
+	path += ` + "->" + strconv.Itoa(i)`
+
+	switch typ := elem.Underlying().(type) {
+	case *types.Struct:
+		if !walkable(elem) {
+			return
+		}
+
+		g.indexes = true
+
+		fmt.Fprintf(&g.body, "for i := range %s {\nenvs = %s(envs, visiting, &%s[i], %s, %s)\n}\n",
+			expr, g.helper(elem), expr, prefix, path,
+		)
+	case *types.Pointer:
+		if walkable(typ.Elem()) {
+			g.indexes = true
+
+			fmt.Fprintf(&g.body, "for i, elem := range %s {\n", expr)
+			g.visit(typ.Elem(), "elem != nil", prefix, path)
+			g.body.WriteString("}\n")
+		}
+	}
+}
+
+func (g *generator) descendKeys(elem types.Type, expr string, prefix string, path string) {
+	path += ` + "->" + fmt.Sprint(key)`
+
+	switch typ := elem.Underlying().(type) {
+	case *types.Struct:
+		if !walkable(elem) {
+			return
+		}
+
+		g.keys = true
+
+		fmt.Fprintf(&g.body, "for _, key := range enwSortedKeys(%s) {\nelem := %s[key]\n", expr, expr)
+		fmt.Fprintf(&g.body, "envs = %s(envs, visiting, &elem, %s, %s)\n}\n", g.helper(elem), prefix, path)
+	case *types.Pointer:
+		if walkable(typ.Elem()) {
+			g.keys = true
+
+			fmt.Fprintf(&g.body, "for _, key := range enwSortedKeys(%s) {\n", expr)
+			g.visit(typ.Elem(), "elem := "+expr+"[key]; elem != nil", prefix, path)
+			g.body.WriteString("}\n")
+		}
+	}
+}
+
+func (g *generator) descendPointer(pointer *types.Pointer, expr string, prefix string, path string) {
+	if walkable(pointer.Elem()) {
+		g.visit(pointer.Elem(), "elem := "+expr+"; elem != nil", prefix, path)
+	}
+}
+
+func (g *generator) visit(elem types.Type, cond string, prefix string, path string) {
+	fmt.Fprintf(&g.body, "if %s && !visiting[elem] {\nvisiting[elem] = true\n", cond)
+	fmt.Fprintf(&g.body, "envs = %s(envs, visiting, elem, %s, %s)\n", g.helper(elem), prefix, path)
+	g.body.WriteString("delete(visiting, elem)\n}\n")
+}
+
+func envFields(env *enw.Env, varExpr string, pathExpr string) string {
+	fields := []string{
+		"Var: " + varExpr,
+		"Field: " + strconv.Quote(env.Field),
+		"Type: " + strconv.Quote(env.Type),
+		"Path: " + pathExpr,
+	}
+
+	if env.Package != "" {
+		fields = append(fields, "Package: "+strconv.Quote(env.Package))
+	}
+
+	return strings.Join(append(fields, "Tag: "+tagLiteral(&env.Tag)), ", ")
+}
+
+func tagLiteral(tag *enw.Tag) string {
+	fields := make([]string, 0)
+
+	appendString := func(name string, value string) {
+		if value != "" {
+			fields = append(fields, name+": "+strconv.Quote(value))
+		}
+	}
+
+	appendBool := func(name string, value bool) {
+		if value {
+			fields = append(fields, name+": true")
+		}
+	}
+
+	appendString("Default", tag.Default)
+	appendString("Delimiter", tag.Delimiter)
+	appendString("Separator", tag.Separator)
+
+	appendBool("Empty", tag.Empty)
+	appendBool("Required", tag.Required)
+	appendBool("Overwrite", tag.Overwrite)
+	appendBool("NoInit", tag.NoInit)
+	appendBool("DecodeUnset", tag.DecodeUnset)
+
+	return "enw.Tag{" + strings.Join(fields, ", ") + "}"
+}
+
+const sortedKeys = `func enwSortedKeys[M ~map[K]V, K comparable, V any](value M) []K {
+	keys := make([]K, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+
+	return keys
+}
+`
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDir = "internal/sample"
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("up to date", func(t *testing.T) {
+		t.Parallel()
+
+		want, err := os.ReadFile(filepath.Join(sampleDir, "config_enw.go"))
+
+		require.NoError(t, err)
+
+		got, err := Generate(sampleDir, "Config", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	})
+
+	tests := []struct {
+		err      error
+		name     string
+		dir      string
+		typeName string
+	}{
+		{name: "type not found", dir: sampleDir, typeName: "Missing", err: ErrTypeNotFound},
+		{name: "not a type", dir: sampleDir, typeName: "ConfigManifest", err: ErrTypeNotFound},
+		{name: "missing package", dir: "missing", typeName: "Config", err: ErrLoadPackage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Generate(test.dir, test.typeName, "env")
+
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	output := filepath.Join(t.TempDir(), "config_enw.go")
+
+	require.ErrorIs(t, run(sampleDir, "", "env", output), ErrMissingType)
+	require.NoError(t, run(sampleDir, "Config", "env", output))
+	assert.FileExists(t, output)
+
+	info, err := os.Stat(output)
+
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
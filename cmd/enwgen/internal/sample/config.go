@@ -0,0 +1,47 @@
+// Package sample keeps the configuration used to check the generated code.
+package sample
+
+import (
+	"time"
+)
+
+//go:generate go run ../.. -type Config
+
+type (
+	Server struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT,default=8080"`
+	}
+
+	Plugin struct {
+		Name    string   `env:"NAME,required"`
+		Options []string `env:"OPTIONS,delimiter=;,default=a;b"`
+	}
+
+	Node struct {
+		Parent   *Node   `env:",prefix=PARENT_"`
+		Name     string  `env:"NAME"`
+		Children []*Node `env:",prefix=CHILD_"`
+	}
+
+	Config struct {
+		Server
+
+		Cache      *Server           `env:",prefix=CACHE_"`
+		Plugins    map[string]Plugin `env:",prefix=PLUGIN_"`
+		PtrPlugins map[int]*Plugin   `env:",prefix=PTR_PLUGIN_"`
+		Any        any               `env:",prefix=ANY_"`
+		Inline     struct {
+			On bool `env:"ON"`
+		} `env:",prefix=INLINE_"`
+		Tree       *Node         `env:",prefix=TREE_"`
+		Name       string        `env:"APP_NAME"`
+		URL        string        `env:"APP_URL,default=http://$HOST:${PORT}"`
+		hidden     string        `env:"HIDDEN"`
+		Timeout    time.Duration `env:"TIMEOUT,overwrite"`
+		Started    *time.Time    `env:"STARTED,noinit"`
+		Servers    []Server      `env:",prefix=SRV_"`
+		PtrServers []*Server     `env:",prefix=PTR_SRV_"`
+		Replicas   [2]Server     `env:",prefix=REPLICA_"`
+	}
+)
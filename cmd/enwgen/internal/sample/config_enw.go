@@ -0,0 +1,165 @@
+// Code generated by enwgen. DO NOT EDIT.
+
+package sample
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/therenotomorrow/enw"
+)
+
+var ConfigManifest = []*enw.Env{
+	{Var: "APP_NAME", Field: "Name", Type: "string", Path: "Config->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+	{Var: "APP_URL", Field: "URL", Type: "string", Path: "Config->URL", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "http://$HOST:${PORT}"}},
+	{Var: "CACHE_HOST", Field: "Host", Type: "string", Path: "Config->Cache->Host", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+	{Var: "CACHE_PORT", Field: "Port", Type: "int", Path: "Config->Cache->Port", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "8080"}},
+	{Var: "HOST", Field: "Host", Type: "string", Path: "Config->Server->Host", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+	{Var: "INLINE_ON", Field: "On", Type: "bool", Path: "Config->Inline->On", Tag: enw.Tag{Empty: true}},
+	{Var: "PLUGIN_NAME", Field: "Name", Type: "string", Path: "Config->Plugins->*->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Required: true}},
+	{Var: "PLUGIN_OPTIONS", Field: "Options", Type: "[]string", Path: "Config->Plugins->*->Options", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "a;b", Delimiter: ";"}},
+	{Var: "PORT", Field: "Port", Type: "int", Path: "Config->Server->Port", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "8080"}},
+	{Var: "PTR_PLUGIN_NAME", Field: "Name", Type: "string", Path: "Config->PtrPlugins->*->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Required: true}},
+	{Var: "PTR_PLUGIN_OPTIONS", Field: "Options", Type: "[]string", Path: "Config->PtrPlugins->*->Options", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "a;b", Delimiter: ";"}},
+	{Var: "PTR_SRV_HOST", Field: "Host", Type: "string", Path: "Config->PtrServers->*->Host", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+	{Var: "PTR_SRV_PORT", Field: "Port", Type: "int", Path: "Config->PtrServers->*->Port", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "8080"}},
+	{Var: "REPLICA_HOST", Field: "Host", Type: "string", Path: "Config->Replicas->*->Host", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+	{Var: "REPLICA_PORT", Field: "Port", Type: "int", Path: "Config->Replicas->*->Port", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "8080"}},
+	{Var: "SRV_HOST", Field: "Host", Type: "string", Path: "Config->Servers->*->Host", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+	{Var: "SRV_PORT", Field: "Port", Type: "int", Path: "Config->Servers->*->Port", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "8080"}},
+	{Var: "STARTED", Field: "Started", Type: "*time.Time", Path: "Config->Started", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{NoInit: true}},
+	{Var: "TIMEOUT", Field: "Timeout", Type: "time.Duration", Path: "Config->Timeout", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Overwrite: true}},
+	{Var: "TREE_NAME", Field: "Name", Type: "string", Path: "Config->Tree->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}},
+}
+
+func CollectConfig(target *Config) ([]*enw.Env, error) {
+	if target == nil {
+		return nil, enw.ErrNilTarget
+	}
+
+	envs := enwCollectConfig0(make([]*enw.Env, 0), map[any]bool{target: true}, target, "", "Config")
+
+	slices.SortStableFunc(envs, func(a, b *enw.Env) int {
+		return cmp.Compare(a.Var, b.Var)
+	})
+
+	return envs, nil
+}
+
+func LoadConfig(ctx context.Context, finder *enw.Finder, target *Config) ([]*enw.Env, error) {
+	envs, err := CollectConfig(target)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, env := range envs {
+		found, err := finder.FindContext(ctx, env)
+
+		switch {
+		case errors.Is(err, enw.ErrEnvNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			envs[i] = found
+		}
+	}
+
+	return envs, nil
+}
+
+func enwCollectConfig0(envs []*enw.Env, visiting map[any]bool, value *Config, prefix string, path string) []*enw.Env {
+	envs = enwCollectServer1(envs, visiting, &value.Server, prefix, path+"->Server")
+	if elem := value.Cache; elem != nil && !visiting[elem] {
+		visiting[elem] = true
+		envs = enwCollectServer1(envs, visiting, elem, prefix+"CACHE_", path+"->Cache")
+		delete(visiting, elem)
+	}
+	for _, key := range enwSortedKeys(value.Plugins) {
+		elem := value.Plugins[key]
+		envs = enwCollectPlugin2(envs, visiting, &elem, prefix+"PLUGIN_", path+"->Plugins"+"->"+fmt.Sprint(key))
+	}
+	for _, key := range enwSortedKeys(value.PtrPlugins) {
+		if elem := value.PtrPlugins[key]; elem != nil && !visiting[elem] {
+			visiting[elem] = true
+			envs = enwCollectPlugin2(envs, visiting, elem, prefix+"PTR_PLUGIN_", path+"->PtrPlugins"+"->"+fmt.Sprint(key))
+			delete(visiting, elem)
+		}
+	}
+	envs = enwCollectStruct3(envs, visiting, &value.Inline, prefix+"INLINE_", path+"->Inline")
+	if elem := value.Tree; elem != nil && !visiting[elem] {
+		visiting[elem] = true
+		envs = enwCollectNode4(envs, visiting, elem, prefix+"TREE_", path+"->Tree")
+		delete(visiting, elem)
+	}
+	envs = append(envs, &enw.Env{Var: prefix + "APP_NAME", Field: "Name", Type: "string", Path: path + "->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}})
+	envs = append(envs, &enw.Env{Var: prefix + "APP_URL", Field: "URL", Type: "string", Path: path + "->URL", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "http://$HOST:${PORT}"}})
+	envs = append(envs, &enw.Env{Var: prefix + "TIMEOUT", Field: "Timeout", Type: "time.Duration", Path: path + "->Timeout", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Overwrite: true}})
+	envs = append(envs, &enw.Env{Var: prefix + "STARTED", Field: "Started", Type: "*time.Time", Path: path + "->Started", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{NoInit: true}})
+	for i := range value.Servers {
+		envs = enwCollectServer1(envs, visiting, &value.Servers[i], prefix+"SRV_", path+"->Servers"+"->"+strconv.Itoa(i))
+	}
+	for i, elem := range value.PtrServers {
+		if elem != nil && !visiting[elem] {
+			visiting[elem] = true
+			envs = enwCollectServer1(envs, visiting, elem, prefix+"PTR_SRV_", path+"->PtrServers"+"->"+strconv.Itoa(i))
+			delete(visiting, elem)
+		}
+	}
+	for i := range value.Replicas {
+		envs = enwCollectServer1(envs, visiting, &value.Replicas[i], prefix+"REPLICA_", path+"->Replicas"+"->"+strconv.Itoa(i))
+	}
+	return envs
+}
+
+func enwCollectServer1(envs []*enw.Env, visiting map[any]bool, value *Server, prefix string, path string) []*enw.Env {
+	envs = append(envs, &enw.Env{Var: prefix + "HOST", Field: "Host", Type: "string", Path: path + "->Host", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}})
+	envs = append(envs, &enw.Env{Var: prefix + "PORT", Field: "Port", Type: "int", Path: path + "->Port", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "8080"}})
+	return envs
+}
+
+func enwCollectPlugin2(envs []*enw.Env, visiting map[any]bool, value *Plugin, prefix string, path string) []*enw.Env {
+	envs = append(envs, &enw.Env{Var: prefix + "NAME", Field: "Name", Type: "string", Path: path + "->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Required: true}})
+	envs = append(envs, &enw.Env{Var: prefix + "OPTIONS", Field: "Options", Type: "[]string", Path: path + "->Options", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Default: "a;b", Delimiter: ";"}})
+	return envs
+}
+
+func enwCollectStruct3(envs []*enw.Env, visiting map[any]bool, value *struct {
+	On bool "env:\"ON\""
+}, prefix string, path string) []*enw.Env {
+	envs = append(envs, &enw.Env{Var: prefix + "ON", Field: "On", Type: "bool", Path: path + "->On", Tag: enw.Tag{Empty: true}})
+	return envs
+}
+
+func enwCollectNode4(envs []*enw.Env, visiting map[any]bool, value *Node, prefix string, path string) []*enw.Env {
+	if elem := value.Parent; elem != nil && !visiting[elem] {
+		visiting[elem] = true
+		envs = enwCollectNode4(envs, visiting, elem, prefix+"PARENT_", path+"->Parent")
+		delete(visiting, elem)
+	}
+	envs = append(envs, &enw.Env{Var: prefix + "NAME", Field: "Name", Type: "string", Path: path + "->Name", Package: "github.com/therenotomorrow/enw/cmd/enwgen/internal/sample", Tag: enw.Tag{Empty: true}})
+	for i, elem := range value.Children {
+		if elem != nil && !visiting[elem] {
+			visiting[elem] = true
+			envs = enwCollectNode4(envs, visiting, elem, prefix+"CHILD_", path+"->Children"+"->"+strconv.Itoa(i))
+			delete(visiting, elem)
+		}
+	}
+	return envs
+}
+
+func enwSortedKeys[M ~map[K]V, K comparable, V any](value M) []K {
+	keys := make([]K, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b K) int {
+		return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	})
+
+	return keys
+}
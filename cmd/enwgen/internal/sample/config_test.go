@@ -0,0 +1,102 @@
+package sample_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/cmd/enwgen/internal/sample"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+	"github.com/therenotomorrow/enw/sources/memory"
+)
+
+func config() *sample.Config {
+	root := &sample.Node{Name: "root"}
+	root.Children = []*sample.Node{{Name: "child", Parent: root}, nil}
+
+	return &sample.Config{
+		Server:     sample.Server{Host: "localhost", Port: 80},
+		Cache:      &sample.Server{},
+		Plugins:    map[string]sample.Plugin{"b": {}, "a": {}},
+		PtrPlugins: map[int]*sample.Plugin{10: {}, 2: nil, 1: {}},
+		Any:        "not a struct",
+		Tree:       root,
+		Started:    &time.Time{},
+		Servers:    []sample.Server{{}, {}},
+		PtrServers: []*sample.Server{nil, {}},
+	}
+}
+
+func TestCollectConfig(t *testing.T) {
+	t.Parallel()
+
+	collector, err := enw.NewCollector(sethvargo.New())
+
+	require.NoError(t, err)
+
+	want, err := collector.Collect(config())
+
+	require.NoError(t, err)
+
+	got, err := sample.CollectConfig(config())
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = sample.CollectConfig(nil)
+
+	require.ErrorIs(t, err, enw.ErrNilTarget)
+}
+
+func TestConfigManifest(t *testing.T) {
+	t.Parallel()
+
+	collector, err := enw.NewCollector(sethvargo.New())
+
+	require.NoError(t, err)
+
+	want, err := enw.CollectType[sample.Config](collector)
+
+	require.NoError(t, err)
+	assert.Equal(t, want, sample.ConfigManifest)
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	finder, err := enw.NewFinder([]enw.NamedSource{
+		{Name: "memory", Source: memory.New(map[string]string{"APP_NAME": "sample", "HOST": "example.com"})},
+	})
+
+	require.NoError(t, err)
+
+	got, err := sample.LoadConfig(t.Context(), finder, config())
+
+	require.NoError(t, err)
+
+	found := make(map[string]string)
+
+	for _, env := range got {
+		if env.Source != "" {
+			found[env.Var] = env.Val
+		}
+	}
+
+	assert.Equal(t, map[string]string{"APP_NAME": "sample", "HOST": "example.com"}, found)
+
+	broken, err := enw.NewFinder([]enw.NamedSource{
+		{Name: "memory", Source: memory.New(nil).WithError(enw.ErrEmptyEnvs)},
+	})
+
+	require.NoError(t, err)
+
+	_, err = sample.LoadConfig(t.Context(), broken, config())
+
+	require.ErrorIs(t, err, enw.ErrEmptyEnvs)
+
+	_, err = sample.LoadConfig(t.Context(), finder, nil)
+
+	require.ErrorIs(t, err, enw.ErrNilTarget)
+}
@@ -0,0 +1,65 @@
+// Command enwgen generates reflection-free collectors for configuration structs.
+//
+// Usage:
+//
+//	//go:generate go run github.com/therenotomorrow/enw/cmd/enwgen -type Config
+//
+// For the type `Config` it writes `config_enw.go` next to the source with:
+//   - `ConfigManifest` - the static inventory, the same as `enw.CollectType[Config]` returns;
+//   - `CollectConfig` - the same as `Collector.Collect(&config)` but without reflection;
+//   - `LoadConfig` - collects the variables and resolves them with the `enw.Finder`.
+//
+// Tags are read with the rules of `parsers/sethvargo`. Structs held by interface
+// fields are not known before the runtime, so they are skipped.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultTagKey = "env"
+	fileMode      = 0o644
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "name of the configuration struct, required")
+		tagKey   = flag.String("tag", defaultTagKey, "struct tag key to read")
+		output   = flag.String("output", "", "output file, default is <type>_enw.go")
+	)
+
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	err := run(dir, *typeName, *tagKey, *output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "enwgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, typeName string, tagKey string, output string) error {
+	if typeName == "" {
+		return ErrMissingType
+	}
+
+	code, err := Generate(dir, typeName, tagKey)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = filepath.Join(dir, strings.ToLower(typeName)+"_enw.go")
+	}
+
+	return os.WriteFile(output, code, fileMode) //nolint:gosec // generated sources are committed like gofmt output
+}
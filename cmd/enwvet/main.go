@@ -0,0 +1,15 @@
+// Command enwvet checks struct tags read by the sethvargo parser.
+//
+// It runs standalone or as a `go vet` tool:
+//
+//	go vet -vettool=$(which enwvet) ./...
+package main
+
+import (
+	"github.com/therenotomorrow/enw/analyzers/envtag"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(envtag.New())
+}
@@ -7,23 +7,107 @@ import (
 	"slices"
 )
 
+const (
+	EmbeddedNest    Embedding = "nest"
+	EmbeddedFlatten Embedding = "flatten"
+
+	ReasonCycle Reason = "cycle"
+	ReasonDepth Reason = "depth"
+
+	Placeholder = "*"
+)
+
 type (
 	Parser interface {
 		Parse(field *reflect.StructField, path string, pkg string) (env *Env, prefix string)
 	}
 
+	Embedding string
+
+	Reason string
+
+	CollectorConfig struct {
+		Parser   Parser
+		Embedded Embedding
+		MaxDepth int
+	}
+
+	Diagnostic struct {
+		Path   string
+		Reason Reason
+	}
+
 	Collector struct {
-		parser    Parser
-		variables []*Env
+		visiting    map[visit]bool
+		cache       map[collection]collected
+		config      CollectorConfig
+		variables   []*Env
+		diagnostics []Diagnostic
+		schema      bool
+	}
+
+	collection struct {
+		rType  reflect.Type
+		schema bool
+	}
+
+	collected struct {
+		variables   []*Env
+		diagnostics []Diagnostic
+	}
+
+	inventoryKey struct {
+		name     string
+		rType    string
+		def      string
+		required bool
+	}
+
+	visit struct {
+		rType reflect.Type
+		ptr   uintptr
+		size  int
 	}
 )
 
 func NewCollector(parser Parser) (*Collector, error) {
-	if parser == nil {
+	return NewCollectorWithConfig(CollectorConfig{Parser: parser, Embedded: EmbeddedNest})
+}
+
+func NewCollectorWithConfig(config CollectorConfig) (*Collector, error) {
+	if config.Parser == nil {
 		return nil, ErrMissingParser
 	}
 
-	return &Collector{parser: parser, variables: make([]*Env, 0)}, nil
+	switch config.Embedded {
+	case "":
+		config.Embedded = EmbeddedNest
+	case EmbeddedNest, EmbeddedFlatten:
+	default:
+		return nil, ErrInvalidEmbedding
+	}
+
+	if config.MaxDepth < 0 {
+		return nil, ErrInvalidMaxDepth
+	}
+
+	return &Collector{
+		config:      config,
+		variables:   make([]*Env, 0),
+		diagnostics: make([]Diagnostic, 0),
+		visiting:    make(map[visit]bool),
+		cache:       make(map[collection]collected),
+	}, nil
+}
+
+func (c *Collector) Config() CollectorConfig {
+	return c.config
+}
+
+// Diagnostics reports the problems of the last collection, the cached
+// collections report their problems again.
+func (c *Collector) Diagnostics() []Diagnostic {
+	return slices.Clone(c.diagnostics)
 }
 
 func (c *Collector) Collect(target any) ([]*Env, error) {
@@ -31,35 +115,127 @@ func (c *Collector) Collect(target any) ([]*Env, error) {
 		return nil, ErrNilTarget
 	}
 
-	if len(c.variables) != 0 {
-		return c.variables, nil
+	rValue := reflect.ValueOf(target)
+	if rValue.Kind() == reflect.Ptr && rValue.IsNil() {
+		return nil, ErrNilTarget
 	}
 
-	rValue := reflect.ValueOf(target)
+	return c.collect(rValue, false)
+}
+
+func (c *Collector) CollectSchema(rType reflect.Type) ([]*Env, error) {
+	if rType == nil {
+		return nil, ErrNilTarget
+	}
+
+	return c.collect(reflect.New(rType).Elem(), true)
+}
 
-	if rValue.Kind() == reflect.Ptr {
-		if rValue.IsNil() {
-			return nil, ErrNilTarget
+func CollectType[T any](collector *Collector) ([]*Env, error) {
+	return collector.CollectSchema(reflect.TypeFor[T]())
+}
+
+func (c *Collector) CollectAll(targets ...any) ([]*Env, error) {
+	if len(targets) == 0 {
+		return nil, ErrMissingTarget
+	}
+
+	inventory := make([]*Env, 0)
+	diagnostics := make([]Diagnostic, 0)
+	seen := make(map[inventoryKey]bool)
+
+	for _, target := range targets {
+		envs, err := c.Collect(target)
+		if err != nil {
+			return nil, err
 		}
 
-		rValue = rValue.Elem()
+		diagnostics = append(diagnostics, c.diagnostics...)
+
+		name := targetName(reflect.TypeOf(target))
+
+		for _, env := range envs {
+			key := inventoryKey{name: env.Var, rType: env.Type, def: env.Tag.Default, required: env.Tag.Required}
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			clone := *env
+			clone.Target = name
+
+			inventory = append(inventory, &clone)
+		}
+	}
+
+	slices.SortStableFunc(inventory, func(a, b *Env) int {
+		return cmp.Compare(a.Var, b.Var)
+	})
+
+	c.diagnostics = diagnostics
+
+	return inventory, nil
+}
+
+func (c *Collector) collect(rValue reflect.Value, schema bool) ([]*Env, error) {
+	rType := rValue.Type()
+	if rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
 	}
 
-	if rValue.Kind() != reflect.Struct {
+	if rType.Kind() != reflect.Struct {
 		return nil, ErrInvalidTarget
 	}
 
-	c.walk(rValue, "", rValue.Type().Name(), rValue.Type().PkgPath())
+	// the values with slices, maps, interfaces or pointers collect their own
+	// variables, so only the schema and the plain structs are cached
+	key := collection{rType: rType, schema: schema}
+	cacheable := schema || static(rType)
+
+	if cached, ok := c.cache[key]; ok && cacheable {
+		c.diagnostics = cached.diagnostics
+
+		return cached.variables, nil
+	}
+
+	c.schema = schema
+	c.variables = make([]*Env, 0)
+	c.diagnostics = make([]Diagnostic, 0)
+	c.walkElem(rValue, "", rType.Name(), 0)
 
 	slices.SortStableFunc(c.variables, func(a, b *Env) int {
 		return cmp.Compare(a.Var, b.Var)
 	})
 
+	if cacheable {
+		c.cache[key] = collected{variables: c.variables, diagnostics: c.diagnostics}
+	}
+
 	return c.variables, nil
 }
 
-func (c *Collector) walk(rValue reflect.Value, currPrefix string, currPath string, currPkg string) {
+// static reports whether every value of the type has the same variables.
+func static(rType reflect.Type) bool {
+	switch rType.Kind() { //nolint:exhaustive // other kinds have no variables inside
+	case reflect.Slice, reflect.Map, reflect.Interface, reflect.Ptr:
+		return false
+	case reflect.Array:
+		return static(rType.Elem())
+	case reflect.Struct:
+		for i := range rType.NumField() {
+			field := rType.Field(i)
+			if field.IsExported() && !static(field.Type) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (c *Collector) walk(rValue reflect.Value, currPrefix string, currPath string, depth int) {
 	rType := rValue.Type()
+	currPkg := rType.PkgPath()
 
 	for i := range rType.NumField() {
 		field := rType.Field(i)
@@ -74,41 +250,147 @@ func (c *Collector) walk(rValue reflect.Value, currPrefix string, currPath strin
 			path = currPath + "->" + path
 		}
 
-		env, prefix := c.parser.Parse(&field, path, currPkg)
+		env, prefix := c.config.Parser.Parse(&field, path, currPkg)
 		if env != nil {
 			env.Var = currPrefix + env.Var
 
 			c.variables = append(c.variables, env)
 		}
 
-		switch fieldValue.Kind() { //nolint:exhaustive // we don't need other kinds here
-		case reflect.Slice, reflect.Array:
-			for j := range fieldValue.Len() {
-				elem := fieldValue.Index(j)
+		if field.Anonymous && c.config.Embedded == EmbeddedFlatten {
+			path = currPath
+		}
+
+		c.walkField(fieldValue, currPrefix+prefix, path, depth+1)
+	}
+}
+
+func (c *Collector) walkField(fieldValue reflect.Value, prefix string, path string, depth int) {
+	for fieldValue.Kind() == reflect.Interface && !fieldValue.IsNil() {
+		fieldValue = fieldValue.Elem()
+	}
+
+	switch fieldValue.Kind() { //nolint:exhaustive // we don't need other kinds here
+	case reflect.Slice, reflect.Array:
+		if c.schema {
+			c.walkElem(reflect.Zero(fieldValue.Type().Elem()), prefix, path+"->"+Placeholder, depth)
 
-				if nested, ok := extractStruct(elem); ok {
-					elemPath := fmt.Sprintf("%s->%d", path, j)
+			return
+		}
 
-					c.walk(nested, currPrefix+prefix, elemPath, nested.Type().PkgPath())
-				}
+		// the elements may refer back to the slice itself
+		if fieldValue.Kind() == reflect.Slice && fieldValue.Len() > 0 {
+			key := visit{rType: fieldValue.Type(), ptr: fieldValue.Pointer(), size: fieldValue.Len()}
+			if !c.enter(key, path) {
+				return
 			}
 
-		default:
-			if nested, ok := extractStruct(fieldValue); ok {
-				c.walk(nested, currPrefix+prefix, path, nested.Type().PkgPath())
+			defer delete(c.visiting, key)
+		}
+
+		for j := range fieldValue.Len() {
+			c.walkElem(fieldValue.Index(j), prefix, fmt.Sprintf("%s->%d", path, j), depth)
+		}
+
+	case reflect.Map:
+		if c.schema {
+			c.walkElem(reflect.Zero(fieldValue.Type().Elem()), prefix, path+"->"+Placeholder, depth)
+
+			return
+		}
+
+		if !fieldValue.IsNil() {
+			key := visit{rType: fieldValue.Type(), ptr: fieldValue.Pointer(), size: 0}
+			if !c.enter(key, path) {
+				return
 			}
+
+			defer delete(c.visiting, key)
+		}
+
+		keys := fieldValue.MapKeys()
+
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+		})
+
+		for _, key := range keys {
+			c.walkElem(fieldValue.MapIndex(key), prefix, fmt.Sprintf("%s->%v", path, key.Interface()), depth)
 		}
+
+	default:
+		c.walkElem(fieldValue, prefix, path, depth)
 	}
 }
 
-func extractStruct(rValue reflect.Value) (reflect.Value, bool) {
-	for rValue.Kind() == reflect.Ptr {
-		if rValue.IsNil() {
-			return rValue, false
+func (c *Collector) walkElem(elem reflect.Value, prefix string, path string, depth int) {
+	var pointer *visit
+
+	for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+		if elem.IsNil() && (!c.schema || elem.Kind() == reflect.Interface) {
+			return
+		}
+
+		if elem.IsNil() {
+			elem = reflect.New(elem.Type().Elem())
 		}
 
-		rValue = rValue.Elem()
+		if elem.Kind() == reflect.Ptr {
+			pointer = &visit{rType: elem.Type(), ptr: elem.Pointer()}
+		}
+
+		elem = elem.Elem()
+	}
+
+	if elem.Kind() != reflect.Struct {
+		return
+	}
+
+	// there are no real pointers behind the zero values, so recursive
+	// types are detected by the struct type itself
+	if c.schema {
+		pointer = &visit{rType: elem.Type(), ptr: 0}
+	}
+
+	if pointer != nil {
+		if !c.enter(*pointer, path) {
+			return
+		}
+
+		defer delete(c.visiting, *pointer)
+	}
+
+	if c.config.MaxDepth != 0 && depth > c.config.MaxDepth {
+		c.diagnostics = append(c.diagnostics, Diagnostic{Path: path, Reason: ReasonDepth})
+
+		return
+	}
+
+	c.walk(elem, prefix, path, depth)
+}
+
+// enter marks the value as being walked, the value that is walked already
+// is reported as the cycle.
+func (c *Collector) enter(key visit, path string) bool {
+	if c.visiting[key] {
+		c.diagnostics = append(c.diagnostics, Diagnostic{Path: path, Reason: ReasonCycle})
+
+		return false
+	}
+
+	c.visiting[key] = true
+
+	return true
+}
+
+func targetName(rType reflect.Type) string {
+	for rType.Kind() == reflect.Ptr {
+		rType = rType.Elem()
+	}
+
+	if rType.PkgPath() == "" {
+		return rType.String()
 	}
 
-	return rValue, rValue.Kind() == reflect.Struct
+	return rType.PkgPath() + "." + rType.Name()
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/parsers/composite"
 	"github.com/therenotomorrow/enw/parsers/sethvargo"
 )
 
@@ -47,10 +48,78 @@ func TestNewCollector(t *testing.T) {
 	}
 }
 
+func TestNewCollectorWithConfig(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		config enw.CollectorConfig
+	}
+
+	type want struct {
+		err    error
+		config enw.CollectorConfig
+	}
+
+	parser := sethvargo.New()
+
+	tests := []struct {
+		want want
+		args args
+		name string
+	}{
+		{
+			name: "default embedding",
+			args: args{config: enw.CollectorConfig{Parser: parser}},
+			want: want{config: enw.CollectorConfig{Parser: parser, Embedded: enw.EmbeddedNest}, err: nil},
+		},
+		{
+			name: "flatten embedding",
+			args: args{config: enw.CollectorConfig{Parser: parser, Embedded: enw.EmbeddedFlatten}},
+			want: want{config: enw.CollectorConfig{Parser: parser, Embedded: enw.EmbeddedFlatten}, err: nil},
+		},
+		{
+			name: "missing parser",
+			args: args{config: enw.CollectorConfig{Embedded: enw.EmbeddedNest}},
+			want: want{err: enw.ErrMissingParser},
+		},
+		{
+			name: "invalid embedding",
+			args: args{config: enw.CollectorConfig{Parser: parser, Embedded: "invalid"}},
+			want: want{err: enw.ErrInvalidEmbedding},
+		},
+		{
+			name: "max depth",
+			args: args{config: enw.CollectorConfig{Parser: parser, MaxDepth: 3}},
+			want: want{config: enw.CollectorConfig{Parser: parser, Embedded: enw.EmbeddedNest, MaxDepth: 3}, err: nil},
+		},
+		{
+			name: "invalid max depth",
+			args: args{config: enw.CollectorConfig{Parser: parser, MaxDepth: -1}},
+			want: want{err: enw.ErrInvalidMaxDepth},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := enw.NewCollectorWithConfig(test.args.config)
+			if test.want.err != nil {
+				require.ErrorIs(t, err, test.want.err)
+				assert.Nil(t, obj)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.want.config, obj.Config())
+			}
+		})
+	}
+}
+
 func TestParsers(t *testing.T) {
 	t.Parallel()
 
 	_ = []enw.Parser{
+		&composite.Parser{},
 		&sethvargo.Parser{},
 	}
 }
@@ -315,4 +384,484 @@ func TestCollectorCollect(t *testing.T) {
 		_, _ = obj.Collect(cacheConf)
 		_, _ = obj.Collect(cacheConf)
 	})
+
+	t.Run("value dependent collection", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		paths := func(target sampleConfig) []string {
+			got, err := obj.Collect(target)
+
+			require.NoError(t, err)
+
+			paths := make([]string, 0, len(got))
+			for _, env := range got {
+				paths = append(paths, env.Path)
+			}
+
+			return paths
+		}
+
+		assert.Equal(t, []string{"sampleConfig->AppName", "sampleConfig->DB->Host", "sampleConfig->DB->Port"},
+			paths(sampleConfig{}))
+		assert.Equal(t, []string{
+			"sampleConfig->AppName", "sampleConfig->Cache->Host", "sampleConfig->Cache->Port",
+			"sampleConfig->DB->Host", "sampleConfig->DB->Port",
+			"sampleConfig->Servers->0->Host", "sampleConfig->Servers->0->Port",
+		}, paths(sampleConfig{Cache: &cacheConf, Servers: []Sample{srv1}}))
+	})
+}
+
+func TestCollectorCollectShapes(t *testing.T) {
+	t.Parallel()
+
+	type Plugin struct {
+		Name string `env:"NAME"`
+	}
+
+	type Base struct {
+		Debug bool `env:"DEBUG"`
+	}
+
+	type sampleConfig struct {
+		Base
+
+		Plugins    map[string]Plugin `env:",prefix=PLUGIN_"`
+		PtrPlugins map[int]*Plugin   `env:",prefix=PTR_PLUGIN_"`
+		Any        any               `env:",prefix=ANY_"`
+		Anys       []any             `env:",prefix=ANYS_"`
+		AnyMap     any               `env:",prefix=ANY_MAP_"`
+		Nil        any               `env:",prefix=NIL_"`
+		Scalars    map[string]string `env:"SCALARS"`
+	}
+
+	target := sampleConfig{
+		Base:       Base{Debug: true},
+		Plugins:    map[string]Plugin{"b": {Name: "b"}, "a": {Name: "a"}},
+		PtrPlugins: map[int]*Plugin{1: {Name: "one"}, 0: nil},
+		Any:        &Plugin{Name: "any"},
+		Anys:       []any{Plugin{Name: "first"}, "skip", nil},
+		AnyMap:     map[string]any{"key": Plugin{Name: "key"}},
+		Nil:        nil,
+		Scalars:    map[string]string{"a": "b"},
+	}
+
+	env := func(name string, field string, typ string, path string) *enw.Env {
+		return &enw.Env{Var: name, Field: field, Type: typ, Path: path, Package: testPackage, Tag: enw.Tag{Empty: true}}
+	}
+
+	tests := []struct {
+		name     string
+		embedded enw.Embedding
+		want     []*enw.Env
+	}{
+		{
+			name:     "nested embedding",
+			embedded: enw.EmbeddedNest,
+			want: []*enw.Env{
+				env("ANYS_NAME", "Name", "string", "sampleConfig->Anys->0->Name"),
+				env("ANY_MAP_NAME", "Name", "string", "sampleConfig->AnyMap->key->Name"),
+				env("ANY_NAME", "Name", "string", "sampleConfig->Any->Name"),
+				env("DEBUG", "Debug", "bool", "sampleConfig->Base->Debug"),
+				env("PLUGIN_NAME", "Name", "string", "sampleConfig->Plugins->a->Name"),
+				env("PLUGIN_NAME", "Name", "string", "sampleConfig->Plugins->b->Name"),
+				env("PTR_PLUGIN_NAME", "Name", "string", "sampleConfig->PtrPlugins->1->Name"),
+				env("SCALARS", "Scalars", "map[string]string", "sampleConfig->Scalars"),
+			},
+		},
+		{
+			name:     "flatten embedding",
+			embedded: enw.EmbeddedFlatten,
+			want: []*enw.Env{
+				env("ANYS_NAME", "Name", "string", "sampleConfig->Anys->0->Name"),
+				env("ANY_MAP_NAME", "Name", "string", "sampleConfig->AnyMap->key->Name"),
+				env("ANY_NAME", "Name", "string", "sampleConfig->Any->Name"),
+				env("DEBUG", "Debug", "bool", "sampleConfig->Debug"),
+				env("PLUGIN_NAME", "Name", "string", "sampleConfig->Plugins->a->Name"),
+				env("PLUGIN_NAME", "Name", "string", "sampleConfig->Plugins->b->Name"),
+				env("PTR_PLUGIN_NAME", "Name", "string", "sampleConfig->PtrPlugins->1->Name"),
+				env("SCALARS", "Scalars", "map[string]string", "sampleConfig->Scalars"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := enw.NewCollectorWithConfig(enw.CollectorConfig{
+				Parser:   sethvargo.New(),
+				Embedded: test.embedded,
+			})
+
+			require.NoError(t, err)
+
+			got, err := obj.Collect(target)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+type node struct {
+	Parent   *node   `env:",prefix=PARENT_"`
+	Self     any     `env:",prefix=SELF_"`
+	Name     string  `env:"NAME"`
+	Children []*node `env:",prefix=CHILD_"`
+}
+
+type sliceNode struct {
+	Name     string      `env:"NAME"`
+	Children []sliceNode `env:",prefix=CHILD_"`
+}
+
+type mapNode struct {
+	Items map[string]any `env:",prefix=ITEM_"`
+	Name  string         `env:"NAME"`
+}
+
+func TestCollectorCollectCycles(t *testing.T) {
+	t.Parallel()
+
+	root := &node{Name: "root"}
+	child := &node{Name: "child", Parent: root}
+	grandchild := &node{Name: "grandchild", Parent: child}
+
+	root.Self = root
+	root.Children = []*node{child}
+	child.Children = []*node{grandchild}
+
+	children := make([]sliceNode, 1)
+	children[0].Children = children
+
+	items := make(map[string]any)
+	items["self"] = mapNode{Items: items, Name: ""}
+
+	type want struct {
+		vars        []string
+		diagnostics []enw.Diagnostic
+	}
+
+	tests := []struct {
+		target   any
+		name     string
+		want     want
+		maxDepth int
+	}{
+		{
+			name:     "unlimited depth",
+			target:   root,
+			maxDepth: 0,
+			want: want{
+				vars: []string{"CHILD_CHILD_NAME", "CHILD_NAME", "NAME"},
+				diagnostics: []enw.Diagnostic{
+					{Path: "node->Self", Reason: enw.ReasonCycle},
+					{Path: "node->Children->0->Parent", Reason: enw.ReasonCycle},
+					{Path: "node->Children->0->Children->0->Parent", Reason: enw.ReasonCycle},
+				},
+			},
+		},
+		{
+			name:     "limited depth",
+			target:   root,
+			maxDepth: 1,
+			want: want{
+				vars: []string{"CHILD_NAME", "NAME"},
+				diagnostics: []enw.Diagnostic{
+					{Path: "node->Self", Reason: enw.ReasonCycle},
+					{Path: "node->Children->0->Parent", Reason: enw.ReasonCycle},
+					{Path: "node->Children->0->Children->0", Reason: enw.ReasonDepth},
+				},
+			},
+		},
+		{
+			name:     "slice self reference",
+			target:   sliceNode{Name: "root", Children: children},
+			maxDepth: 0,
+			want: want{
+				vars: []string{"CHILD_NAME", "NAME"},
+				diagnostics: []enw.Diagnostic{
+					{Path: "sliceNode->Children->0->Children", Reason: enw.ReasonCycle},
+				},
+			},
+		},
+		{
+			name:     "map self reference",
+			target:   mapNode{Items: items, Name: "root"},
+			maxDepth: 0,
+			want: want{
+				vars: []string{"ITEM_NAME", "NAME"},
+				diagnostics: []enw.Diagnostic{
+					{Path: "mapNode->Items->self->Items", Reason: enw.ReasonCycle},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := enw.NewCollectorWithConfig(enw.CollectorConfig{
+				Parser:   sethvargo.New(),
+				MaxDepth: test.maxDepth,
+			})
+
+			require.NoError(t, err)
+
+			got, err := obj.Collect(test.target)
+
+			require.NoError(t, err)
+
+			vars := make([]string, 0, len(got))
+			for _, env := range got {
+				vars = append(vars, env.Var)
+			}
+
+			assert.Equal(t, test.want.vars, vars)
+			assert.Equal(t, test.want.diagnostics, obj.Diagnostics())
+		})
+	}
+}
+
+func TestCollectType(t *testing.T) {
+	t.Parallel()
+
+	type Sample struct {
+		Host string `env:"HOST"`
+	}
+
+	type sampleConfig struct {
+		Cache    *Sample           `env:",prefix=CACHE_"`
+		Plugins  map[string]Sample `env:",prefix=PLUGIN_"`
+		Any      any               `env:",prefix=ANY_"`
+		Servers  []Sample          `env:",prefix=SRV_"`
+		Replicas [2]*Sample        `env:",prefix=REPLICA_"`
+	}
+
+	env := func(name string, path string) *enw.Env {
+		return &enw.Env{
+			Var:     name,
+			Field:   "Host",
+			Type:    "string",
+			Path:    path,
+			Package: testPackage,
+			Tag:     enw.Tag{Empty: true},
+		}
+	}
+
+	want := []*enw.Env{
+		env("CACHE_HOST", "sampleConfig->Cache->Host"),
+		env("PLUGIN_HOST", "sampleConfig->Plugins->*->Host"),
+		env("REPLICA_HOST", "sampleConfig->Replicas->*->Host"),
+		env("SRV_HOST", "sampleConfig->Servers->*->Host"),
+	}
+
+	t.Run("value type", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		got, err := enw.CollectType[sampleConfig](obj)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Empty(t, obj.Diagnostics())
+	})
+
+	t.Run("pointer type", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		got, err := enw.CollectType[*sampleConfig](obj)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("recursive type", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		got, err := enw.CollectType[node](obj)
+
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+		assert.Equal(t, "NAME", got[0].Var)
+		assert.Equal(t, []enw.Diagnostic{
+			{Path: "node->Parent", Reason: enw.ReasonCycle},
+			{Path: "node->Children->*", Reason: enw.ReasonCycle},
+		}, obj.Diagnostics())
+	})
+
+	t.Run("diagnostics per collection", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		cycles := []enw.Diagnostic{
+			{Path: "node->Parent", Reason: enw.ReasonCycle},
+			{Path: "node->Children->*", Reason: enw.ReasonCycle},
+		}
+
+		_, err = enw.CollectType[node](obj)
+
+		require.NoError(t, err)
+		assert.Equal(t, cycles, obj.Diagnostics())
+
+		_, err = enw.CollectType[sampleConfig](obj)
+
+		require.NoError(t, err)
+		assert.Empty(t, obj.Diagnostics())
+
+		_, err = enw.CollectType[node](obj)
+
+		require.NoError(t, err)
+		assert.Equal(t, cycles, obj.Diagnostics())
+
+		looped := &node{Name: "root"}
+		looped.Self = looped
+
+		_, err = obj.CollectAll(sampleConfig{}, looped)
+
+		require.NoError(t, err)
+		assert.Equal(t, []enw.Diagnostic{{Path: "node->Self", Reason: enw.ReasonCycle}}, obj.Diagnostics())
+	})
+
+	t.Run("invalid types", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		_, err = enw.CollectType[int](obj)
+
+		require.ErrorIs(t, err, enw.ErrInvalidTarget)
+
+		_, err = obj.CollectSchema(nil)
+
+		require.ErrorIs(t, err, enw.ErrNilTarget)
+	})
+}
+
+func TestCollectorCollectAll(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `env:"HOST"`
+	}
+
+	type billingConfig struct {
+		DB   Database `env:",prefix=DB_"`
+		Port int      `env:"BILLING_PORT"`
+	}
+
+	type ordersConfig struct {
+		DB    Database `env:",prefix=DB_"`
+		Limit int      `env:"ORDERS_LIMIT,default=10"`
+	}
+
+	t.Run("per type cache", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		billing, err := obj.Collect(billingConfig{})
+
+		require.NoError(t, err)
+
+		orders, err := obj.Collect(&ordersConfig{})
+
+		require.NoError(t, err)
+
+		again, err := obj.Collect(&billingConfig{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "BILLING_PORT", billing[0].Var)
+		assert.Equal(t, "DB_HOST", orders[0].Var)
+		assert.Equal(t, "ORDERS_LIMIT", orders[1].Var)
+		assert.Same(t, billing[0], again[0])
+	})
+
+	t.Run("merged inventory", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		got, err := obj.CollectAll(billingConfig{}, &ordersConfig{}, billingConfig{})
+
+		require.NoError(t, err)
+
+		want := []*enw.Env{
+			{
+				Var:     "BILLING_PORT",
+				Field:   "Port",
+				Type:    "int",
+				Path:    "billingConfig->Port",
+				Package: testPackage,
+				Target:  testPackage + ".billingConfig",
+				Tag:     enw.Tag{Empty: true},
+			},
+			{
+				Var:     "DB_HOST",
+				Field:   "Host",
+				Type:    "string",
+				Path:    "billingConfig->DB->Host",
+				Package: testPackage,
+				Target:  testPackage + ".billingConfig",
+				Tag:     enw.Tag{Empty: true},
+			},
+			{
+				Var:     "ORDERS_LIMIT",
+				Field:   "Limit",
+				Type:    "int",
+				Path:    "ordersConfig->Limit",
+				Package: testPackage,
+				Target:  testPackage + ".ordersConfig",
+				Tag:     enw.Tag{Default: "10"},
+			},
+		}
+
+		assert.Equal(t, want, got)
+
+		cached, err := obj.Collect(billingConfig{})
+
+		require.NoError(t, err)
+		assert.Empty(t, cached[0].Target)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewCollector(sethvargo.New())
+
+		require.NoError(t, err)
+
+		_, err = obj.CollectAll()
+
+		require.ErrorIs(t, err, enw.ErrMissingTarget)
+
+		_, err = obj.CollectAll(billingConfig{}, nil)
+
+		require.ErrorIs(t, err, enw.ErrNilTarget)
+	})
 }
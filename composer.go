@@ -3,19 +3,23 @@ package enw
 import (
 	"context"
 	"errors"
+	"sync"
 )
 
 type (
 	Config struct {
 		Parser   Parser
 		Target   any
+		Hooks    Hooks
 		Sources  []NamedSource
 		Autoload bool
 	}
 	Composer struct {
 		collector *Collector
 		finder    *Finder
+		index     map[string]*Env
 		config    Config
+		mu        sync.Mutex
 	}
 )
 
@@ -25,16 +29,17 @@ func NewComposer(config Config) (*Composer, error) {
 		return nil, err
 	}
 
-	finder, err := NewFinder(config.Sources)
+	finder, err := NewFinderWithConfig(FinderConfig{Hooks: config.Hooks, Sources: config.Sources})
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = collector.Collect(config.Target)
-	comp := &Composer{config: config, collector: collector, finder: finder}
+	envs, err := collector.Collect(config.Target)
+	comp := &Composer{config: config, collector: collector, finder: finder, index: index(envs), mu: sync.Mutex{}}
 
 	if config.Autoload {
-		err = errors.Join(err, finder.load(context.Background()))
+		_, loadErr := finder.load(context.Background())
+		err = errors.Join(err, loadErr)
 	}
 
 	if err != nil {
@@ -44,14 +49,74 @@ func NewComposer(config Config) (*Composer, error) {
 	return comp, err
 }
 
+// Collect is safe for the concurrent use, the collector is shared.
 func (c *Composer) Collect() ([]*Env, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.collector.Collect(c.config.Target)
 }
 
+func (c *Composer) Reload(ctx context.Context) error {
+	return c.finder.Reload(ctx)
+}
+
+// Find returns the Var, Val and Source of the found variable, the hooks
+// receive the collected variable with its tag.
 func (c *Composer) Find(env string) *Env {
-	return c.finder.Find(New(env))
+	return result(c.finder.Find(c.env(env)))
+}
+
+func (c *Composer) FindContext(ctx context.Context, env string) (*Env, error) {
+	found, err := c.finder.FindContext(ctx, c.env(env))
+	if err != nil {
+		return nil, err
+	}
+
+	return result(found), nil
 }
 
 func (c *Composer) Search(env string) []*Env {
-	return c.finder.Search(New(env))
+	envs := c.finder.Search(c.env(env))
+	for i, found := range envs {
+		envs[i] = result(found)
+	}
+
+	return envs
+}
+
+// env returns the collected variable, so the hooks know about the default,
+// the unknown names are looked up as they are.
+func (c *Composer) env(name string) *Env {
+	if env, ok := c.index[name]; ok {
+		return env
+	}
+
+	return New(name)
+}
+
+// index maps the names to the collected variables, the first one wins for
+// the same name.
+func index(envs []*Env) map[string]*Env {
+	envsByVar := make(map[string]*Env, len(envs))
+
+	for _, env := range envs {
+		if _, ok := envsByVar[env.Var]; !ok {
+			envsByVar[env.Var] = env
+		}
+	}
+
+	return envsByVar
+}
+
+func result(found *Env) *Env {
+	if found == nil {
+		return nil
+	}
+
+	env := New(found.Var)
+	env.Val = found.Val
+	env.Source = found.Source
+
+	return env
 }
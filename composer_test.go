@@ -125,6 +125,15 @@ func TestComposerFind(t *testing.T) {
 	assert.Nil(t, got)
 }
 
+func TestComposerFindContext(t *testing.T) {
+	t.Parallel()
+
+	got, err := newComposer().FindContext(t.Context(), "mad") // just a proxy
+
+	require.ErrorIs(t, err, enw.ErrEnvNotFound)
+	assert.Nil(t, got)
+}
+
 func TestComposerSearch(t *testing.T) {
 	t.Parallel()
 
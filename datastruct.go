@@ -1,13 +1,21 @@
 package enw
 
 import (
+	"os"
+	"slices"
+
 	"github.com/therenotomorrow/ex"
 )
 
 type Tag struct {
-	Default  string
-	Empty    bool
-	Required bool
+	Default     string
+	Delimiter   string
+	Separator   string
+	Empty       bool
+	Required    bool
+	Overwrite   bool
+	NoInit      bool
+	DecodeUnset bool
 }
 
 type Env struct {
@@ -18,9 +26,26 @@ type Env struct {
 	Val     string
 	Package string
 	Source  string
+	Target  string
 	Tag     Tag
 }
 
+// Expands lists the variables the default refers to, go-envconfig expands
+// them when the default is used.
+func (t Tag) Expands() []string {
+	var vars []string
+
+	os.Expand(t.Default, func(name string) string {
+		if !slices.Contains(vars, name) {
+			vars = append(vars, name)
+		}
+
+		return ""
+	})
+
+	return vars
+}
+
 func New(key string) *Env {
 	env := new(Env)
 	env.Var = key
@@ -33,12 +58,15 @@ func (e *Env) Anonymous() bool {
 }
 
 const (
-	ErrMissingTarget   ex.Const = "missing target"
-	ErrNilTarget       ex.Const = "nil target"
-	ErrInvalidTarget   ex.Const = "invalid target, must be struct or pointer to struct"
-	ErrMissingParser   ex.Const = "missing parser"
-	ErrMissingSources  ex.Const = "missing sources"
-	ErrNotUniqueSource ex.Const = "not unique source"
-	ErrEmptyEnvs       ex.Const = "empty envs"
-	ErrEnvNotFound     ex.Const = "env not found"
+	ErrMissingTarget    ex.Const = "missing target"
+	ErrNilTarget        ex.Const = "nil target"
+	ErrInvalidTarget    ex.Const = "invalid target, must be struct or pointer to struct"
+	ErrMissingParser    ex.Const = "missing parser"
+	ErrMissingSources   ex.Const = "missing sources"
+	ErrNotUniqueSource  ex.Const = "not unique source"
+	ErrEmptyEnvs        ex.Const = "empty envs"
+	ErrEnvNotFound      ex.Const = "env not found"
+	ErrConflictingEnv   ex.Const = "conflicting env"
+	ErrInvalidEmbedding ex.Const = "invalid embedding, must be nest or flatten"
+	ErrInvalidMaxDepth  ex.Const = "invalid max depth, must not be negative"
 )
@@ -13,12 +13,33 @@ func TestTag(t *testing.T) {
 
 	// `exhaustruct` + `types` testing
 	_ = enw.Tag{
-		Default:  "default",
-		Empty:    true,
-		Required: true,
+		Default:     "default",
+		Delimiter:   ",",
+		Separator:   ":",
+		Empty:       true,
+		Required:    true,
+		Overwrite:   true,
+		NoInit:      true,
+		DecodeUnset: true,
 	}
 }
 
+func TestComparable(t *testing.T) {
+	t.Parallel()
+
+	// the tags and the envs are used as map keys downstream
+	seen := map[enw.Env]bool{{Var: "VAR", Tag: enw.Tag{Default: "$HOST"}}: true}
+
+	assert.True(t, seen[enw.Env{Var: "VAR", Tag: enw.Tag{Default: "$HOST"}}])
+}
+
+func TestTagExpands(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, enw.Tag{Default: "plain"}.Expands())
+	assert.Equal(t, []string{"HOST", "PORT"}, enw.Tag{Default: "http://${HOST}:$PORT/$HOST"}.Expands())
+}
+
 func TestEnv(t *testing.T) {
 	t.Parallel()
 
@@ -31,7 +52,8 @@ func TestEnv(t *testing.T) {
 		Val:     "val",
 		Package: "package",
 		Source:  "source",
-		Tag:     enw.Tag{Default: "default", Empty: true, Required: true},
+		Target:  "target",
+		Tag:     enw.Tag{Default: "default", Empty: true, Required: true, Overwrite: true},
 	}
 }
 
@@ -59,6 +81,9 @@ func TestErrorConsistency(t *testing.T) {
 		"missing sources",
 		"empty envs",
 		"not unique source",
+		"conflicting env",
+		"invalid embedding, must be nest or flatten",
+		"invalid max depth, must not be negative",
 	}
 
 	for _, err := range []ex.Const{
@@ -69,6 +94,9 @@ func TestErrorConsistency(t *testing.T) {
 		enw.ErrMissingSources,
 		enw.ErrEmptyEnvs,
 		enw.ErrNotUniqueSource,
+		enw.ErrConflictingEnv,
+		enw.ErrInvalidEmbedding,
+		enw.ErrInvalidMaxDepth,
 	} {
 		got = append(got, err.Error())
 	}
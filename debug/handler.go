@@ -0,0 +1,235 @@
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/diff"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+
+	queryPrefix = "prefix"
+	queryPath   = "path"
+	queryFormat = "format"
+
+	ErrMissingComposer ex.Const = "missing composer"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>enw</title></head>
+<body>
+<table>
+<tr><th>Variable</th><th>Value</th><th>Source</th><th>Default</th><th>Path</th><th>Type</th></tr>
+{{- range .}}
+<tr>
+<td><code>{{.Var}}</code>{{if .Required}} *{{end}}</td>
+<td>{{if .Missing}}<em>missing</em>{{else}}<code>{{.Val}}</code>{{end}}</td>
+<td>{{if .Defaulted}}<em>default</em>{{else}}{{.Source}}{{end}}</td>
+<td><code>{{.Default}}</code></td>
+<td>{{.Path}}</td>
+<td>{{.Type}}</td>
+</tr>
+{{- end}}
+</table>
+</body>
+</html>
+`
+
+type (
+	Format string
+
+	Config struct {
+		Composer  *enw.Composer
+		Sensitive func(env *enw.Env) bool
+	}
+
+	Variable struct {
+		Var       string `json:"var"`
+		Path      string `json:"path"`
+		Type      string `json:"type"`
+		Val       string `json:"val"`
+		Source    string `json:"source"`
+		Default   string `json:"default"`
+		Defaulted bool   `json:"defaulted"`
+		Required  bool   `json:"required"`
+		Missing   bool   `json:"missing"`
+		Redacted  bool   `json:"redacted"`
+	}
+
+	Handler struct {
+		page   *template.Template
+		config Config
+	}
+
+	response struct {
+		Variables []Variable `json:"variables"`
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.Composer == nil {
+		return ErrMissingComposer
+	}
+
+	return nil
+}
+
+func New(composer *enw.Composer) (*Handler, error) {
+	return NewWithConfig(Config{Composer: composer, Sensitive: diff.Sensitive})
+}
+
+func NewWithConfig(config Config) (*Handler, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{page: template.Must(template.New("page").Parse(page)), config: config}, nil
+}
+
+func (h *Handler) Config() Config {
+	return h.config
+}
+
+// ServeHTTP responds with JSON unless `?format=html` is asked or the client
+// accepts HTML. `?prefix=` filters by the variable name and `?path=` by the
+// field path. The requests are served concurrently, the composer serializes
+// the collection and the finder swaps the reloaded values under its lock.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	variables, err := h.variables(r, query.Get(queryPrefix), query.Get(queryPath))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	format := Format(query.Get(queryFormat))
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/html") {
+		format = FormatHTML
+	}
+
+	switch format {
+	case "", FormatJSON:
+		writeJSON(w, variables)
+	case FormatHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		_ = h.page.Execute(w, variables)
+	default:
+		http.Error(w, "unknown format "+string(format), http.StatusBadRequest)
+	}
+}
+
+func (h *Handler) variables(r *http.Request, prefix string, path string) ([]Variable, error) {
+	envs, err := h.config.Composer.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make([]Variable, 0, len(envs))
+
+	for _, env := range envs {
+		if !strings.HasPrefix(env.Var, prefix) || !strings.HasPrefix(env.Path, path) {
+			continue
+		}
+
+		found, err := h.config.Composer.FindContext(r.Context(), env.Var)
+		if err != nil && !errors.Is(err, enw.ErrEnvNotFound) {
+			return nil, err
+		}
+
+		variable, err := h.variable(r.Context(), env, found)
+		if err != nil {
+			return nil, err
+		}
+
+		variables = append(variables, variable)
+	}
+
+	return variables, nil
+}
+
+func (h *Handler) variable(ctx context.Context, env *enw.Env, found *enw.Env) (Variable, error) {
+	variable := Variable{
+		Var:       env.Var,
+		Path:      env.Path,
+		Type:      env.Type,
+		Val:       "",
+		Source:    "",
+		Default:   env.Tag.Default,
+		Defaulted: false,
+		Required:  env.Tag.Required,
+		Missing:   false,
+		Redacted:  false,
+	}
+
+	switch {
+	case found != nil:
+		variable.Val = found.Val
+		variable.Source = found.Source
+	case env.Tag.Default != "":
+		val, err := h.expand(ctx, env.Tag.Default)
+		if err != nil {
+			return Variable{}, err
+		}
+
+		variable.Val = val
+		variable.Defaulted = true
+	default:
+		variable.Missing = true
+	}
+
+	if h.config.Sensitive != nil && h.config.Sensitive(env) {
+		variable.Redacted = true
+		variable.Val = diff.Redacted
+
+		if variable.Default != "" {
+			variable.Default = diff.Redacted
+		}
+	}
+
+	return variable, nil
+}
+
+// expand resolves the variables in the default as go-envconfig does, the
+// ones that are not found are replaced with the empty string.
+func (h *Handler) expand(ctx context.Context, value string) (string, error) {
+	var errs error
+
+	expanded := os.Expand(value, func(name string) string {
+		found, err := h.config.Composer.FindContext(ctx, name)
+		if err != nil {
+			if !errors.Is(err, enw.ErrEnvNotFound) {
+				errs = errors.Join(errs, err)
+			}
+
+			return ""
+		}
+
+		return found.Val
+	})
+
+	return expanded, errs
+}
+
+func writeJSON(w http.ResponseWriter, variables []Variable) {
+	w.Header().Set("Content-Type", "application/json")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	_ = encoder.Encode(response{Variables: variables})
+}
@@ -0,0 +1,206 @@
+package debug_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/debug"
+	"github.com/therenotomorrow/enw/diff"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+	"github.com/therenotomorrow/enw/sources/memory"
+	"github.com/therenotomorrow/ex"
+)
+
+type (
+	database struct {
+		Host     string `env:"HOST,required"`
+		Password string `env:"PASSWORD,default=<b>"`
+	}
+
+	config struct {
+		Database database `env:",prefix=DB_"`
+		Port     int      `env:"PORT,default=80"`
+		URL      string   `env:"URL,default=http://${DB_HOST}:$DB_PORT/"`
+		Debug    bool     `env:"DEBUG"`
+	}
+)
+
+func composer(source enw.Source) *enw.Composer {
+	return ex.Must(enw.NewComposer(enw.Config{
+		Parser:   sethvargo.New(),
+		Sources:  []enw.NamedSource{{Name: "memory", Source: source}},
+		Target:   config{},
+		Autoload: false,
+	}))
+}
+
+func serve(t *testing.T, handler http.Handler, target string, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, target, nil)
+	req.Header.Set("Accept", accept)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestNewWithConfig(t *testing.T) {
+	t.Parallel()
+
+	obj, err := debug.NewWithConfig(debug.Config{})
+
+	require.ErrorIs(t, err, debug.ErrMissingComposer)
+	assert.Nil(t, obj)
+
+	obj, err = debug.New(composer(memory.New(nil)))
+
+	require.NoError(t, err)
+	assert.NotNil(t, obj.Config().Sensitive)
+}
+
+func TestHandlerJSON(t *testing.T) {
+	t.Parallel()
+
+	handler, err := debug.New(composer(memory.New(map[string]string{"DB_HOST": "db", "DB_PASSWORD": "secret"})))
+
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		target string
+		want   []debug.Variable
+	}{
+		{
+			name:   "all",
+			target: "/",
+			want: []debug.Variable{
+				{
+					Var: "DB_HOST", Path: "config->Database->Host", Type: "string",
+					Val: "db", Source: "memory", Required: true,
+				},
+				{
+					Var: "DB_PASSWORD", Path: "config->Database->Password", Type: "string",
+					Val: diff.Redacted, Source: "memory", Default: diff.Redacted, Redacted: true,
+				},
+				{Var: "DEBUG", Path: "config->Debug", Type: "bool", Missing: true},
+				{Var: "PORT", Path: "config->Port", Type: "int", Val: "80", Default: "80", Defaulted: true},
+				{
+					Var: "URL", Path: "config->URL", Type: "string", Val: "http://db:/",
+					Default: "http://${DB_HOST}:$DB_PORT/", Defaulted: true,
+				},
+			},
+		},
+		{
+			name:   "prefix",
+			target: "/?prefix=DE&format=json",
+			want:   []debug.Variable{{Var: "DEBUG", Path: "config->Debug", Type: "bool", Missing: true}},
+		},
+		{
+			name:   "path",
+			target: "/?path=config-%3EPort",
+			want: []debug.Variable{
+				{Var: "PORT", Path: "config->Port", Type: "int", Val: "80", Default: "80", Defaulted: true},
+			},
+		},
+		{name: "nothing", target: "/?prefix=MISSING", want: []debug.Variable{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := serve(t, handler, test.target, "application/json")
+
+			var got struct {
+				Variables []debug.Variable `json:"variables"`
+			}
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, test.want, got.Variables)
+		})
+	}
+}
+
+func TestHandlerHTML(t *testing.T) {
+	t.Parallel()
+
+	handler, err := debug.NewWithConfig(debug.Config{
+		Composer:  composer(memory.New(map[string]string{"DB_HOST": "<script>"})),
+		Sensitive: nil,
+	})
+
+	require.NoError(t, err)
+
+	for _, rec := range []*httptest.ResponseRecorder{
+		serve(t, handler, "/", "text/html,application/xhtml+xml"),
+		serve(t, handler, "/?format=html", ""),
+	} {
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "<td><code>DB_HOST</code> *</td>")
+		assert.Contains(t, rec.Body.String(), "<td><code>&lt;script&gt;</code></td>")
+		assert.Contains(t, rec.Body.String(), "<td><code>&lt;b&gt;</code></td>")
+		assert.Contains(t, rec.Body.String(), "<td><em>missing</em></td>")
+		assert.Contains(t, rec.Body.String(), "<td><em>default</em></td>")
+	}
+}
+
+func TestHandlerFailure(t *testing.T) {
+	t.Parallel()
+
+	handler, err := debug.New(composer(memory.New(nil)))
+
+	require.NoError(t, err)
+
+	rec := serve(t, handler, "/?format=yaml", "")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	handler, err = debug.New(composer(memory.New(nil).WithError(enw.ErrEmptyEnvs)))
+
+	require.NoError(t, err)
+
+	rec = serve(t, handler, "/", "")
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), enw.ErrEmptyEnvs.Error())
+}
+
+func TestHandlerParallel(t *testing.T) {
+	t.Parallel()
+
+	comp := composer(memory.New(map[string]string{"DB_HOST": "db"}))
+	handler, err := debug.New(comp)
+
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for range 8 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			assert.Equal(t, http.StatusOK, serve(t, handler, "/", "").Code)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, comp.Reload(t.Context()))
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,188 @@
+package diff
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	OnlyInA       Status = "only in a"
+	OnlyInB       Status = "only in b"
+	ValueChanged  Status = "value"
+	SourceChanged Status = "source"
+
+	Redacted = "<redacted>"
+
+	defaultNameA = "a"
+	defaultNameB = "b"
+
+	ErrMissingFinder ex.Const = "missing finder"
+)
+
+var sensitiveName = regexp.MustCompile(`(?i)pass|secret|token|key|credential|private|auth`)
+
+type (
+	Status string
+
+	Config struct {
+		Sensitive func(env *enw.Env) bool
+		NameA     string
+		NameB     string
+	}
+
+	Side struct {
+		Val    string `json:"val"`
+		Source string `json:"source"`
+	}
+
+	Change struct {
+		A        *Side    `json:"a"`
+		B        *Side    `json:"b"`
+		Var      string   `json:"var"`
+		Path     string   `json:"path"`
+		Statuses []Status `json:"statuses"`
+	}
+
+	Report struct {
+		NameA   string   `json:"nameA"`
+		NameB   string   `json:"nameB"`
+		Changes []Change `json:"changes"`
+	}
+
+	Differ struct {
+		config Config
+	}
+)
+
+// Sensitive reports the variables which names look like credentials.
+func Sensitive(env *enw.Env) bool {
+	return sensitiveName.MatchString(env.Var)
+}
+
+func New() *Differ {
+	return NewWithConfig(Config{Sensitive: Sensitive, NameA: defaultNameA, NameB: defaultNameB})
+}
+
+func NewWithConfig(config Config) *Differ {
+	config.NameA = cmp.Or(config.NameA, defaultNameA)
+	config.NameB = cmp.Or(config.NameB, defaultNameB)
+
+	return &Differ{config: config}
+}
+
+func (d *Differ) Config() Config {
+	return d.config
+}
+
+func (d *Differ) CompareSources(ctx context.Context, a, b []enw.NamedSource, envs []*enw.Env) (*Report, error) {
+	finderA, err := enw.NewFinder(a)
+	if err != nil {
+		return nil, err
+	}
+
+	finderB, err := enw.NewFinder(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Compare(ctx, finderA, finderB, envs)
+}
+
+// Compare resolves every variable in both finders and reports the ones that
+// differ, values of the sensitive variables are compared but never shown.
+func (d *Differ) Compare(ctx context.Context, a, b *enw.Finder, envs []*enw.Env) (*Report, error) {
+	if a == nil || b == nil {
+		return nil, ErrMissingFinder
+	}
+
+	report := &Report{NameA: d.config.NameA, NameB: d.config.NameB, Changes: make([]Change, 0)}
+	seen := make(map[string]bool)
+
+	for _, env := range envs {
+		if env == nil || seen[env.Var] {
+			continue
+		}
+
+		seen[env.Var] = true
+
+		foundA, err := find(ctx, a, env)
+		if err != nil {
+			return nil, err
+		}
+
+		foundB, err := find(ctx, b, env)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses := compare(foundA, foundB)
+		if len(statuses) == 0 {
+			continue
+		}
+
+		report.Changes = append(report.Changes, Change{
+			A:        d.side(foundA),
+			B:        d.side(foundB),
+			Var:      env.Var,
+			Path:     env.Path,
+			Statuses: statuses,
+		})
+	}
+
+	slices.SortFunc(report.Changes, func(a, b Change) int {
+		return cmp.Compare(a.Var, b.Var)
+	})
+
+	return report, nil
+}
+
+func (d *Differ) side(env *enw.Env) *Side {
+	if env == nil {
+		return nil
+	}
+
+	val := env.Val
+	if d.config.Sensitive != nil && d.config.Sensitive(env) {
+		val = Redacted
+	}
+
+	return &Side{Val: val, Source: env.Source}
+}
+
+func find(ctx context.Context, finder *enw.Finder, env *enw.Env) (*enw.Env, error) {
+	found, err := finder.FindContext(ctx, env)
+	if errors.Is(err, enw.ErrEnvNotFound) {
+		return nil, nil //nolint:nilnil // not found is not an error here
+	}
+
+	return found, err
+}
+
+func compare(a, b *enw.Env) []Status {
+	switch {
+	case a == nil && b == nil:
+		return nil
+	case b == nil:
+		return []Status{OnlyInA}
+	case a == nil:
+		return []Status{OnlyInB}
+	}
+
+	statuses := make([]Status, 0)
+
+	if a.Val != b.Val {
+		statuses = append(statuses, ValueChanged)
+	}
+
+	if a.Source != b.Source {
+		statuses = append(statuses, SourceChanged)
+	}
+
+	return statuses
+}
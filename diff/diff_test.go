@@ -0,0 +1,161 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/diff"
+	"github.com/therenotomorrow/enw/sources/memory"
+)
+
+func staging() []enw.NamedSource {
+	return []enw.NamedSource{
+		{Name: "configmap", Source: memory.New(map[string]string{"HOST": "stage", "PORT": "80", "DEBUG": "true"})},
+		{Name: "secret", Source: memory.New(map[string]string{"DB_PASSWORD": "qwerty", "TOKEN": "abc"})},
+	}
+}
+
+func production() []enw.NamedSource {
+	return []enw.NamedSource{
+		{Name: "secret", Source: memory.New(map[string]string{"DB_PASSWORD": "s3cr3t", "TOKEN": "abc", "PORT": "80"})},
+		{Name: "configmap", Source: memory.New(map[string]string{"HOST": "prod", "PORT": "80", "WORKERS": "8"})},
+	}
+}
+
+func envs() []*enw.Env {
+	return []*enw.Env{
+		{Var: "HOST", Path: "Config->Host"},
+		{Var: "PORT", Path: "Config->Port"},
+		{Var: "DEBUG", Path: "Config->Debug"},
+		{Var: "WORKERS", Path: "Config->Workers"},
+		{Var: "TOKEN", Path: "Config->Token"},
+		{Var: "DB_PASSWORD", Path: "Config->Database->Password"},
+		{Var: "DB_PASSWORD", Path: "Config->Replica->Password"},
+		{Var: "MISSING", Path: "Config->Missing"},
+		nil,
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj := diff.New()
+
+	assert.Equal(t, "a", obj.Config().NameA)
+	assert.Equal(t, "b", obj.Config().NameB)
+	assert.NotNil(t, obj.Config().Sensitive)
+}
+
+func TestSensitive(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"DB_PASSWORD", "API_KEY", "GITHUB_TOKEN", "client_secret", "AUTH_HEADER"} {
+		assert.True(t, diff.Sensitive(enw.New(name)), name)
+	}
+
+	for _, name := range []string{"HOST", "PORT", "DEBUG"} {
+		assert.False(t, diff.Sensitive(enw.New(name)), name)
+	}
+}
+
+func TestDifferCompareSources(t *testing.T) {
+	t.Parallel()
+
+	obj := diff.NewWithConfig(diff.Config{Sensitive: diff.Sensitive, NameA: "staging", NameB: "production"})
+
+	got, err := obj.CompareSources(t.Context(), staging(), production(), envs())
+	want := &diff.Report{
+		NameA: "staging",
+		NameB: "production",
+		Changes: []diff.Change{
+			{
+				A:        &diff.Side{Val: diff.Redacted, Source: "secret"},
+				B:        &diff.Side{Val: diff.Redacted, Source: "secret"},
+				Var:      "DB_PASSWORD",
+				Path:     "Config->Database->Password",
+				Statuses: []diff.Status{diff.ValueChanged},
+			},
+			{
+				A:        &diff.Side{Val: "true", Source: "configmap"},
+				B:        nil,
+				Var:      "DEBUG",
+				Path:     "Config->Debug",
+				Statuses: []diff.Status{diff.OnlyInA},
+			},
+			{
+				A:        &diff.Side{Val: "stage", Source: "configmap"},
+				B:        &diff.Side{Val: "prod", Source: "configmap"},
+				Var:      "HOST",
+				Path:     "Config->Host",
+				Statuses: []diff.Status{diff.ValueChanged},
+			},
+			{
+				A:        &diff.Side{Val: "80", Source: "configmap"},
+				B:        &diff.Side{Val: "80", Source: "secret"},
+				Var:      "PORT",
+				Path:     "Config->Port",
+				Statuses: []diff.Status{diff.SourceChanged},
+			},
+			{
+				A:        nil,
+				B:        &diff.Side{Val: "8", Source: "configmap"},
+				Var:      "WORKERS",
+				Path:     "Config->Workers",
+				Statuses: []diff.Status{diff.OnlyInB},
+			},
+		},
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestDifferCompareSourcesFailure(t *testing.T) {
+	t.Parallel()
+
+	obj := diff.New()
+	broken := []enw.NamedSource{{Name: "memory", Source: memory.New(nil).WithError(enw.ErrEmptyEnvs)}}
+
+	tests := []struct {
+		err  error
+		name string
+		a    []enw.NamedSource
+		b    []enw.NamedSource
+	}{
+		{name: "missing a", a: nil, b: production(), err: enw.ErrMissingSources},
+		{name: "missing b", a: staging(), b: nil, err: enw.ErrMissingSources},
+		{name: "broken a", a: broken, b: production(), err: enw.ErrEmptyEnvs},
+		{name: "broken b", a: staging(), b: broken, err: enw.ErrEmptyEnvs},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := obj.CompareSources(t.Context(), test.a, test.b, envs())
+
+			require.ErrorIs(t, err, test.err)
+			assert.Nil(t, got)
+		})
+	}
+}
+
+func TestDifferCompare(t *testing.T) {
+	t.Parallel()
+
+	finder, err := enw.NewFinder(staging())
+
+	require.NoError(t, err)
+
+	got, err := diff.NewWithConfig(diff.Config{}).Compare(t.Context(), finder, finder, envs())
+
+	require.NoError(t, err)
+	assert.Equal(t, &diff.Report{NameA: "a", NameB: "b", Changes: []diff.Change{}}, got)
+
+	got, err = diff.New().Compare(t.Context(), finder, nil, envs())
+
+	require.ErrorIs(t, err, diff.ErrMissingFinder)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,199 @@
+package diff
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/therenotomorrow/enw"
+)
+
+const (
+	Added           Kind = "added"
+	Removed         Kind = "removed"
+	Renamed         Kind = "renamed"
+	DefaultChanged  Kind = "default changed"
+	RequiredToggled Kind = "required toggled"
+	TypeChanged     Kind = "type changed"
+	PathMoved       Kind = "path moved"
+)
+
+type (
+	Kind string
+
+	Modification struct {
+		Kind Kind   `json:"kind"`
+		Var  string `json:"var"`
+		Path string `json:"path"`
+		Old  string `json:"old"`
+		New  string `json:"new"`
+	}
+
+	Inventory struct {
+		Modifications []Modification `json:"modifications"`
+	}
+)
+
+// Inventories compares two collected inventories. Variables are matched by
+// name, and a removed variable is a rename when an added one took its path.
+func Inventories(before, after []*enw.Env) *Inventory {
+	old, current := index(before), index(after)
+	mods := make([]Modification, 0)
+	renamed := make(map[string]bool)
+
+	for name, env := range current {
+		if prev, ok := old[name]; ok {
+			mods = append(mods, modified(prev, env)...)
+
+			continue
+		}
+
+		prev := renamedFrom(old, current, env)
+		if prev == nil || renamed[prev.Var] {
+			mods = append(mods, Modification{Kind: Added, Var: name, Path: env.Path, Old: "", New: name})
+
+			continue
+		}
+
+		renamed[prev.Var] = true
+
+		mods = append(mods, Modification{Kind: Renamed, Var: name, Path: env.Path, Old: prev.Var, New: name})
+		mods = append(mods, modified(prev, env)...)
+	}
+
+	for name, env := range old {
+		if _, ok := current[name]; !ok && !renamed[name] {
+			mods = append(mods, Modification{Kind: Removed, Var: name, Path: env.Path, Old: name, New: ""})
+		}
+	}
+
+	slices.SortFunc(mods, func(a, b Modification) int {
+		return cmp.Or(cmp.Compare(a.Var, b.Var), cmp.Compare(a.Kind, b.Kind))
+	})
+
+	return &Inventory{Modifications: mods}
+}
+
+func (i *Inventory) Render(w io.Writer, format Format) error {
+	switch format {
+	case FormatText:
+		return i.text(w)
+	case FormatMarkdown:
+		return i.markdown(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(i)
+	default:
+		return ErrInvalidFormat
+	}
+}
+
+func (i *Inventory) text(w io.Writer) error {
+	if len(i.Modifications) == 0 {
+		_, err := fmt.Fprintln(w, "no changes in environment variables")
+
+		return err
+	}
+
+	for _, mod := range i.Modifications {
+		_, err := fmt.Fprintf(w, "%s (%s) at %s%s\n", mod.Var, mod.Kind, mod.Path, transition(mod, strconv.Quote))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Inventory) markdown(w io.Writer) error {
+	if len(i.Modifications) == 0 {
+		_, err := fmt.Fprintln(w, "No changes in environment variables.")
+
+		return err
+	}
+
+	_, err := fmt.Fprint(w, "| Variable | Change | Path | Details |\n| --- | --- | --- | --- |\n")
+	if err != nil {
+		return err
+	}
+
+	code := func(text string) string {
+		return "`" + strings.ReplaceAll(text, "|", `\|`) + "`"
+	}
+
+	for _, mod := range i.Modifications {
+		details := strings.TrimPrefix(transition(mod, code), ": ")
+
+		_, err = fmt.Fprintf(w, "| `%s` | %s | `%s` | %s |\n", mod.Var, mod.Kind, mod.Path, details)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func transition(mod Modification, quote func(text string) string) string {
+	switch mod.Kind { //nolint:exhaustive // added and removed have nothing to compare
+	case Renamed, DefaultChanged, RequiredToggled, TypeChanged, PathMoved:
+		return ": " + quote(mod.Old) + " -> " + quote(mod.New)
+	default:
+		return ""
+	}
+}
+
+func index(envs []*enw.Env) map[string]*enw.Env {
+	indexed := make(map[string]*enw.Env)
+
+	for _, env := range envs {
+		if env == nil {
+			continue
+		}
+
+		// the same variable may be declared at several paths, the first
+		// one in the path order represents it
+		if prev, ok := indexed[env.Var]; !ok || env.Path < prev.Path {
+			indexed[env.Var] = env
+		}
+	}
+
+	return indexed
+}
+
+func renamedFrom(old, current map[string]*enw.Env, env *enw.Env) *enw.Env {
+	candidates := make([]*enw.Env, 0)
+
+	for name, prev := range old {
+		if _, ok := current[name]; !ok && prev.Path == env.Path {
+			candidates = append(candidates, prev)
+		}
+	}
+
+	if len(candidates) != 1 {
+		return nil
+	}
+
+	return candidates[0]
+}
+
+func modified(prev, env *enw.Env) []Modification {
+	mods := make([]Modification, 0)
+	add := func(kind Kind, old string, current string) {
+		if old != current {
+			mods = append(mods, Modification{Kind: kind, Var: env.Var, Path: env.Path, Old: old, New: current})
+		}
+	}
+
+	add(DefaultChanged, prev.Tag.Default, env.Tag.Default)
+	add(RequiredToggled, strconv.FormatBool(prev.Tag.Required), strconv.FormatBool(env.Tag.Required))
+	add(TypeChanged, prev.Type, env.Type)
+	add(PathMoved, prev.Path, env.Path)
+
+	return mods
+}
@@ -0,0 +1,98 @@
+package diff_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/diff"
+)
+
+func TestInventories(t *testing.T) {
+	t.Parallel()
+
+	before := []*enw.Env{
+		{Var: "HOST", Path: "Config->Host", Type: "string"},
+		{Var: "PORT", Path: "Config->Port", Type: "int", Tag: enw.Tag{Default: "80"}},
+		{Var: "PORT", Path: "Config->Admin->Port", Type: "int"},
+		{Var: "DEBUG", Path: "Config->Debug", Type: "bool"},
+		{Var: "USER", Path: "Config->User", Type: "string"},
+		nil,
+	}
+	after := []*enw.Env{
+		{Var: "HOST", Path: "Config->Host", Type: "string", Tag: enw.Tag{Required: true}},
+		{Var: "PORT", Path: "Config->Admin->Port", Type: "uint"},
+		{Var: "VERBOSE", Path: "Config->Debug", Type: "bool"},
+		{Var: "TOKEN", Path: "Config->Token", Type: "string"},
+	}
+
+	got := diff.Inventories(before, after)
+	want := &diff.Inventory{Modifications: []diff.Modification{
+		{Kind: diff.RequiredToggled, Var: "HOST", Path: "Config->Host", Old: "false", New: "true"},
+		{Kind: diff.TypeChanged, Var: "PORT", Path: "Config->Admin->Port", Old: "int", New: "uint"},
+		{Kind: diff.Added, Var: "TOKEN", Path: "Config->Token", Old: "", New: "TOKEN"},
+		{Kind: diff.Removed, Var: "USER", Path: "Config->User", Old: "USER", New: ""},
+		{Kind: diff.Renamed, Var: "VERBOSE", Path: "Config->Debug", Old: "DEBUG", New: "VERBOSE"},
+	}}
+
+	assert.Equal(t, want, got)
+	assert.Equal(t, &diff.Inventory{Modifications: []diff.Modification{}}, diff.Inventories(after, after))
+}
+
+func TestInventoryRender(t *testing.T) {
+	t.Parallel()
+
+	inventory := &diff.Inventory{Modifications: []diff.Modification{
+		{Kind: diff.DefaultChanged, Var: "HOST", Path: "Config->Host", Old: "a|b", New: ""},
+		{Kind: diff.Added, Var: "TOKEN", Path: "Config->Token", Old: "", New: "TOKEN"},
+	}}
+	empty := &diff.Inventory{Modifications: []diff.Modification{}}
+
+	tests := []struct {
+		inventory *diff.Inventory
+		name      string
+		format    diff.Format
+		want      string
+	}{
+		{
+			name:      "text",
+			inventory: inventory,
+			format:    diff.FormatText,
+			want:      "HOST (default changed) at Config->Host: \"a|b\" -> \"\"\nTOKEN (added) at Config->Token\n",
+		},
+		{name: "text empty", inventory: empty, format: diff.FormatText, want: "no changes in environment variables\n"},
+		{
+			name:      "markdown",
+			inventory: inventory,
+			format:    diff.FormatMarkdown,
+			want: "| Variable | Change | Path | Details |\n" +
+				"| --- | --- | --- | --- |\n" +
+				"| `HOST` | default changed | `Config->Host` | `a\\|b` -> `` |\n" +
+				"| `TOKEN` | added | `Config->Token` |  |\n",
+		},
+		{
+			name:      "markdown empty",
+			inventory: empty,
+			format:    diff.FormatMarkdown,
+			want:      "No changes in environment variables.\n",
+		},
+		{name: "json", inventory: empty, format: diff.FormatJSON, want: "{\n  \"modifications\": []\n}\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			err := test.inventory.Render(&buf, test.format)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, buf.String())
+		})
+	}
+
+	require.ErrorIs(t, inventory.Render(new(bytes.Buffer), "yaml"), diff.ErrInvalidFormat)
+}
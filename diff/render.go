@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+
+	missing = "-"
+
+	ErrInvalidFormat ex.Const = "invalid format, must be text, markdown or json"
+)
+
+type Format string
+
+func (r *Report) Render(w io.Writer, format Format) error {
+	switch format {
+	case FormatText:
+		return r.text(w)
+	case FormatMarkdown:
+		return r.markdown(w)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(r)
+	default:
+		return ErrInvalidFormat
+	}
+}
+
+func (r *Report) text(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "no differences between %s and %s\n", r.NameA, r.NameB)
+
+		return err
+	}
+
+	for _, change := range r.Changes {
+		_, err := fmt.Fprintf(w, "%s (%s): %s %s, %s %s\n",
+			change.Var, statuses(change), r.NameA, sideText(change.A), r.NameB, sideText(change.B))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *Report) markdown(w io.Writer) error {
+	if len(r.Changes) == 0 {
+		_, err := fmt.Fprintf(w, "No differences between **%s** and **%s**.\n", r.NameA, r.NameB)
+
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "| Variable | Change | %s | %s |\n| --- | --- | --- | --- |\n", r.NameA, r.NameB)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range r.Changes {
+		_, err = fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n",
+			change.Var, statuses(change), sideMarkdown(change.A), sideMarkdown(change.B))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func statuses(change Change) string {
+	texts := make([]string, 0, len(change.Statuses))
+	for _, status := range change.Statuses {
+		texts = append(texts, string(status))
+	}
+
+	return strings.Join(texts, ", ")
+}
+
+func sideText(side *Side) string {
+	if side == nil {
+		return missing
+	}
+
+	return fmt.Sprintf("%q from %s", side.Val, side.Source)
+}
+
+func sideMarkdown(side *Side) string {
+	if side == nil {
+		return missing
+	}
+
+	val := strings.ReplaceAll(side.Val, "|", `\|`)
+
+	return fmt.Sprintf("`%s` from %s", val, side.Source)
+}
@@ -0,0 +1,115 @@
+package diff_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/diff"
+)
+
+func report() *diff.Report {
+	return &diff.Report{
+		NameA: "staging",
+		NameB: "production",
+		Changes: []diff.Change{
+			{
+				A:        &diff.Side{Val: "a|b", Source: "configmap"},
+				B:        &diff.Side{Val: "c", Source: "secret"},
+				Var:      "HOST",
+				Path:     "Config->Host",
+				Statuses: []diff.Status{diff.ValueChanged, diff.SourceChanged},
+			},
+			{
+				A:        nil,
+				B:        &diff.Side{Val: "8", Source: "configmap"},
+				Var:      "WORKERS",
+				Path:     "Config->Workers",
+				Statuses: []diff.Status{diff.OnlyInB},
+			},
+		},
+	}
+}
+
+func TestReportRender(t *testing.T) {
+	t.Parallel()
+
+	empty := &diff.Report{NameA: "a", NameB: "b", Changes: []diff.Change{}}
+
+	tests := []struct {
+		report *diff.Report
+		name   string
+		format diff.Format
+		want   string
+	}{
+		{
+			name:   "text",
+			report: report(),
+			format: diff.FormatText,
+			want: "HOST (value, source): staging \"a|b\" from configmap, production \"c\" from secret\n" +
+				"WORKERS (only in b): staging -, production \"8\" from configmap\n",
+		},
+		{
+			name:   "text empty",
+			report: empty,
+			format: diff.FormatText,
+			want:   "no differences between a and b\n",
+		},
+		{
+			name:   "markdown",
+			report: report(),
+			format: diff.FormatMarkdown,
+			want: "| Variable | Change | staging | production |\n" +
+				"| --- | --- | --- | --- |\n" +
+				"| `HOST` | value, source | `a\\|b` from configmap | `c` from secret |\n" +
+				"| `WORKERS` | only in b | - | `8` from configmap |\n",
+		},
+		{
+			name:   "markdown empty",
+			report: empty,
+			format: diff.FormatMarkdown,
+			want:   "No differences between **a** and **b**.\n",
+		},
+		{
+			name:   "json",
+			report: empty,
+			format: diff.FormatJSON,
+			want:   "{\n  \"nameA\": \"a\",\n  \"nameB\": \"b\",\n  \"changes\": []\n}\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			err := test.report.Render(&buf, test.format)
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, buf.String())
+		})
+	}
+}
+
+func TestReportRenderJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	err := report().Render(&buf, diff.FormatJSON)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"a": null`)
+	assert.Contains(t, buf.String(), `"statuses": [`)
+	assert.Contains(t, buf.String(), `"source": "secret"`)
+}
+
+func TestReportRenderInvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	err := report().Render(new(bytes.Buffer), "yaml")
+
+	require.ErrorIs(t, err, diff.ErrInvalidFormat)
+}
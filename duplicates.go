@@ -0,0 +1,98 @@
+package enw
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+)
+
+const (
+	MismatchType     Mismatch = "type"
+	MismatchDefault  Mismatch = "default"
+	MismatchRequired Mismatch = "required"
+)
+
+type (
+	Mismatch string
+
+	Duplicate struct {
+		Var        string
+		Paths      []string
+		Mismatches []Mismatch
+	}
+)
+
+func (d *Duplicate) Conflicting() bool {
+	return len(d.Mismatches) != 0
+}
+
+func Duplicates(envs []*Env) []Duplicate {
+	groups := make(map[string][]*Env)
+
+	for _, env := range envs {
+		if env != nil {
+			groups[env.Var] = append(groups[env.Var], env)
+		}
+	}
+
+	duplicates := make([]Duplicate, 0)
+
+	for name, group := range groups {
+		if len(group) < 2 { //nolint:mnd // duplicate means at least two
+			continue
+		}
+
+		paths := make([]string, 0, len(group))
+		for _, env := range group {
+			paths = append(paths, env.Path)
+		}
+
+		duplicates = append(duplicates, Duplicate{Var: name, Paths: paths, Mismatches: mismatches(group[0], group[1:])})
+	}
+
+	slices.SortFunc(duplicates, func(a, b Duplicate) int {
+		return cmp.Compare(a.Var, b.Var)
+	})
+
+	return duplicates
+}
+
+func mismatches(first *Env, others []*Env) []Mismatch {
+	found := make([]Mismatch, 0)
+
+	if slices.ContainsFunc(others, func(env *Env) bool { return env.Type != first.Type }) {
+		found = append(found, MismatchType)
+	}
+
+	if slices.ContainsFunc(others, func(env *Env) bool { return env.Tag.Default != first.Tag.Default }) {
+		found = append(found, MismatchDefault)
+	}
+
+	if slices.ContainsFunc(others, func(env *Env) bool { return env.Tag.Required != first.Tag.Required }) {
+		found = append(found, MismatchRequired)
+	}
+
+	return found
+}
+
+func CheckDuplicates(envs []*Env) error {
+	errs := make([]error, 0)
+
+	for _, duplicate := range Duplicates(envs) {
+		if !duplicate.Conflicting() {
+			continue
+		}
+
+		mismatches := make([]string, 0, len(duplicate.Mismatches))
+		for _, mismatch := range duplicate.Mismatches {
+			mismatches = append(mismatches, string(mismatch))
+		}
+
+		reason := duplicate.Var + " (" + strings.Join(mismatches, ", ") + ") at " + strings.Join(duplicate.Paths, ", ")
+
+		errs = append(errs, ErrConflictingEnv.Reason(reason))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package enw_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+)
+
+func TestDuplicates(t *testing.T) {
+	t.Parallel()
+
+	type Sample struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT,default=80"`
+	}
+
+	type Other struct {
+		Host string `env:"HOST"`
+		Port string `env:"PORT,required"`
+	}
+
+	type sampleConfig struct {
+		First  Sample `env:",prefix=A_"`
+		Second Sample `env:",prefix=A_"`
+		Third  Other
+		Fourth Sample
+	}
+
+	collector, err := enw.NewCollector(sethvargo.New())
+
+	require.NoError(t, err)
+
+	envs, err := collector.Collect(sampleConfig{})
+
+	require.NoError(t, err)
+
+	want := []enw.Duplicate{
+		{
+			Var:        "A_HOST",
+			Paths:      []string{"sampleConfig->First->Host", "sampleConfig->Second->Host"},
+			Mismatches: []enw.Mismatch{},
+		},
+		{
+			Var:        "A_PORT",
+			Paths:      []string{"sampleConfig->First->Port", "sampleConfig->Second->Port"},
+			Mismatches: []enw.Mismatch{},
+		},
+		{
+			Var:        "HOST",
+			Paths:      []string{"sampleConfig->Third->Host", "sampleConfig->Fourth->Host"},
+			Mismatches: []enw.Mismatch{},
+		},
+		{
+			Var:        "PORT",
+			Paths:      []string{"sampleConfig->Third->Port", "sampleConfig->Fourth->Port"},
+			Mismatches: []enw.Mismatch{enw.MismatchType, enw.MismatchDefault, enw.MismatchRequired},
+		},
+	}
+
+	got := enw.Duplicates(envs)
+
+	assert.Equal(t, want, got)
+	assert.False(t, got[0].Conflicting())
+	assert.True(t, got[3].Conflicting())
+	assert.Empty(t, enw.Duplicates(nil))
+	assert.Empty(t, enw.Duplicates([]*enw.Env{enw.New("A"), enw.New("B"), nil}))
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		name string
+		envs []*enw.Env
+	}{
+		{name: "no envs", envs: nil, err: nil},
+		{
+			name: "same attributes",
+			envs: []*enw.Env{{Var: "A", Type: "int", Path: "a"}, {Var: "A", Type: "int", Path: "b"}},
+			err:  nil,
+		},
+		{
+			name: "different types",
+			envs: []*enw.Env{{Var: "A", Type: "int", Path: "a"}, {Var: "A", Type: "string", Path: "b"}},
+			err:  enw.ErrConflictingEnv,
+		},
+		{
+			name: "different defaults",
+			envs: []*enw.Env{{Var: "A", Tag: enw.Tag{Default: "1"}}, {Var: "A", Tag: enw.Tag{Default: "2"}}},
+			err:  enw.ErrConflictingEnv,
+		},
+		{
+			name: "different required",
+			envs: []*enw.Env{{Var: "A", Tag: enw.Tag{Required: true}}, {Var: "A"}},
+			err:  enw.ErrConflictingEnv,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := enw.CheckDuplicates(test.envs)
+
+			require.ErrorIs(t, err, test.err)
+
+			if test.err == nil {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+
+		err := enw.CheckDuplicates([]*enw.Env{
+			{Var: "A", Type: "int", Path: "x->A"},
+			{Var: "A", Type: "string", Path: "y->A", Tag: enw.Tag{Required: true}},
+		})
+
+		require.EqualError(t, err, "conflicting env: A (type, required) at x->A, y->A")
+	})
+}
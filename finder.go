@@ -3,6 +3,8 @@ package enw
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/therenotomorrow/ex"
 )
@@ -17,19 +19,29 @@ type (
 		Name   string
 	}
 
+	FinderConfig struct {
+		Hooks   Hooks
+		Sources []NamedSource
+	}
+
 	Finder struct {
 		storage map[string]map[string]string
-		sources []NamedSource
+		config  FinderConfig
+		mu      sync.RWMutex
 	}
 )
 
 func NewFinder(sources []NamedSource) (*Finder, error) {
-	if len(sources) == 0 {
+	return NewFinderWithConfig(FinderConfig{Hooks: NoHooks{}, Sources: sources})
+}
+
+func NewFinderWithConfig(config FinderConfig) (*Finder, error) {
+	if len(config.Sources) == 0 {
 		return nil, ErrMissingSources
 	}
 
 	uniq := make(map[string]bool)
-	for _, source := range sources {
+	for _, source := range config.Sources {
 		if uniq[source.Name] {
 			return nil, ErrNotUniqueSource
 		}
@@ -37,7 +49,15 @@ func NewFinder(sources []NamedSource) (*Finder, error) {
 		uniq[source.Name] = true
 	}
 
-	return &Finder{sources: sources, storage: nil}, nil
+	if config.Hooks == nil {
+		config.Hooks = NoHooks{}
+	}
+
+	return &Finder{config: config, storage: nil, mu: sync.RWMutex{}}, nil
+}
+
+func (f *Finder) Config() FinderConfig {
+	return f.config
 }
 
 func (f *Finder) Find(env *Env) *Env {
@@ -53,15 +73,25 @@ func (f *Finder) Find(env *Env) *Env {
 }
 
 func (f *Finder) FindContext(ctx context.Context, env *Env) (*Env, error) {
-	err := f.load(ctx)
+	storage, err := f.load(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, source := range f.sources {
-		env, ok := f.find(env, source)
+	for _, source := range f.config.Sources {
+		found, ok := find(storage, env, source)
 		if ok {
-			return env, nil
+			f.config.Hooks.Find(ctx, found, true)
+
+			return found, nil
+		}
+	}
+
+	if env != nil {
+		f.config.Hooks.Find(ctx, env, false)
+
+		if env.Tag.Default != "" {
+			f.config.Hooks.DefaultApplied(ctx, env)
 		}
 	}
 
@@ -73,15 +103,15 @@ func (f *Finder) Search(env *Env) []*Env {
 }
 
 func (f *Finder) SearchContext(ctx context.Context, env *Env) ([]*Env, error) {
-	err := f.load(ctx)
+	storage, err := f.load(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	envs := make([]*Env, 0)
 
-	for _, source := range f.sources {
-		env, ok := f.find(env, source)
+	for _, source := range f.config.Sources {
+		env, ok := find(storage, env, source)
 		if !ok {
 			continue
 		}
@@ -92,12 +122,12 @@ func (f *Finder) SearchContext(ctx context.Context, env *Env) ([]*Env, error) {
 	return envs, nil
 }
 
-func (f *Finder) find(env *Env, source NamedSource) (*Env, bool) {
+func find(storage map[string]map[string]string, env *Env, source NamedSource) (*Env, bool) {
 	if env == nil {
 		return nil, false
 	}
 
-	val, ok := f.storage[source.Name][env.Var]
+	val, ok := storage[source.Name][env.Var]
 	if !ok {
 		return nil, false
 	}
@@ -110,21 +140,67 @@ func (f *Finder) find(env *Env, source NamedSource) (*Env, bool) {
 	return &clone, true
 }
 
-func (f *Finder) load(ctx context.Context) error {
-	if len(f.storage) != 0 {
-		return nil
+// Reload extracts the values from the sources again, the extracted ones are
+// replaced only when every source succeeds.
+func (f *Finder) Reload(ctx context.Context) error {
+	f.config.Hooks.Reload(ctx)
+
+	storage, err := f.extract(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.storage = storage
+	f.mu.Unlock()
+
+	return nil
+}
+
+// load extracts the values once, the concurrent first calls wait for it.
+func (f *Finder) load(ctx context.Context) (map[string]map[string]string, error) {
+	f.mu.RLock()
+	storage := f.storage
+	f.mu.RUnlock()
+
+	if storage != nil {
+		return storage, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.storage != nil {
+		return f.storage, nil
+	}
+
+	storage, err := f.extract(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	f.storage = make(map[string]map[string]string)
+	f.storage = storage
+
+	return storage, nil
+}
+
+func (f *Finder) extract(ctx context.Context) (map[string]map[string]string, error) {
+	storage := make(map[string]map[string]string)
 
-	for _, source := range f.sources {
+	for _, source := range f.config.Sources {
+		f.config.Hooks.LoadStart(ctx, source.Name)
+
+		start := time.Now()
 		data, err := source.Source.Extract(ctx)
+
+		f.config.Hooks.LoadFinish(ctx, source.Name, time.Since(start), len(data), err)
+
 		if err != nil {
-			return ex.From(err)
+			return nil, ex.From(err)
 		}
 
-		f.storage[source.Name] = data
+		storage[source.Name] = data
 	}
 
-	return nil
+	return storage, nil
 }
@@ -1,15 +1,29 @@
 package enw_test
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/sources/aws"
+	"github.com/therenotomorrow/enw/sources/compose"
+	"github.com/therenotomorrow/enw/sources/consul"
 	"github.com/therenotomorrow/enw/sources/dotenv"
+	"github.com/therenotomorrow/enw/sources/etcd"
+	"github.com/therenotomorrow/enw/sources/exec"
+	enwhttp "github.com/therenotomorrow/enw/sources/http"
 	"github.com/therenotomorrow/enw/sources/k8s"
 	"github.com/therenotomorrow/enw/sources/memory"
+	"github.com/therenotomorrow/enw/sources/proc"
+	"github.com/therenotomorrow/enw/sources/sops"
 	"github.com/therenotomorrow/enw/sources/system"
+	"github.com/therenotomorrow/enw/sources/systemd"
+	"github.com/therenotomorrow/enw/sources/vault"
+	"github.com/therenotomorrow/ex"
 )
 
 func TestNewFinder(t *testing.T) {
@@ -63,10 +77,20 @@ func TestSources(t *testing.T) {
 	t.Parallel()
 
 	_ = []enw.Source{
+		&aws.Source{},
+		&compose.Source{},
+		&consul.Source{},
 		&dotenv.Source{},
+		&etcd.Source{},
+		&exec.Source{},
+		&enwhttp.Source{},
 		&k8s.Source{},
 		&memory.Source{},
+		&proc.Source{},
+		&sops.Source{},
 		&system.Source{},
+		&systemd.Source{},
+		&vault.Source{},
 	}
 }
 
@@ -352,3 +376,60 @@ func TestFinderSearchContext(t *testing.T) {
 		_, _ = obj.SearchContext(t.Context(), new(enw.Env))
 	})
 }
+
+// flaky succeeds only on the first extraction.
+type flaky struct {
+	calls atomic.Int32
+}
+
+func (f *flaky) Extract(context.Context) (map[string]string, error) {
+	if f.calls.Add(1) > 1 {
+		return nil, enw.ErrEmptyEnvs
+	}
+
+	return map[string]string{"VAR_F": "val_F"}, nil
+}
+
+func TestFinderReload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed reload keeps values", func(t *testing.T) {
+		t.Parallel()
+
+		obj := ex.Must(enw.NewFinder(append(sources(), enw.NamedSource{Name: "flaky", Source: new(flaky)})))
+
+		require.NotNil(t, obj.Find(enw.New("VAR_F")))
+		require.ErrorIs(t, obj.Reload(t.Context()), enw.ErrEmptyEnvs)
+
+		got, err := obj.FindContext(t.Context(), enw.New("VAR_F"))
+
+		require.NoError(t, err)
+		assert.Equal(t, &enw.Env{Var: "VAR_F", Val: "val_F", Source: "flaky"}, got)
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		t.Parallel()
+
+		obj := ex.Must(enw.NewFinder(sources()))
+
+		var wg sync.WaitGroup
+
+		for range 8 {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+
+				assert.Equal(t, "val_A1", obj.Find(enw.New("VAR_A")).Val)
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				assert.NoError(t, obj.Reload(t.Context()))
+			}()
+		}
+
+		wg.Wait()
+	})
+}
@@ -0,0 +1,29 @@
+package enw
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	Hooks interface {
+		LoadStart(ctx context.Context, source string)
+		LoadFinish(ctx context.Context, source string, duration time.Duration, keys int, err error)
+		Find(ctx context.Context, env *Env, hit bool)
+		DefaultApplied(ctx context.Context, env *Env)
+		Reload(ctx context.Context)
+	}
+
+	// NoHooks ignores every event, embed it to implement only the needed ones.
+	NoHooks struct{}
+)
+
+func (NoHooks) LoadStart(context.Context, string) {}
+
+func (NoHooks) LoadFinish(context.Context, string, time.Duration, int, error) {}
+
+func (NoHooks) Find(context.Context, *Env, bool) {}
+
+func (NoHooks) DefaultApplied(context.Context, *Env) {}
+
+func (NoHooks) Reload(context.Context) {}
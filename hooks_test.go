@@ -0,0 +1,146 @@
+package enw_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+	"github.com/therenotomorrow/enw/sources/memory"
+)
+
+type recorder struct {
+	enw.NoHooks
+
+	events []string
+}
+
+func (r *recorder) LoadStart(_ context.Context, source string) {
+	r.events = append(r.events, "start "+source)
+}
+
+func (r *recorder) LoadFinish(_ context.Context, source string, duration time.Duration, keys int, err error) {
+	r.events = append(r.events, fmt.Sprintf("finish %s %d %v %t", source, keys, err, duration >= 0))
+}
+
+func (r *recorder) Find(_ context.Context, env *enw.Env, hit bool) {
+	r.events = append(r.events, fmt.Sprintf("find %s %s %t", env.Var, env.Source, hit))
+}
+
+func (r *recorder) DefaultApplied(_ context.Context, env *enw.Env) {
+	r.events = append(r.events, "default "+env.Var)
+}
+
+func (r *recorder) Reload(context.Context) {
+	r.events = append(r.events, "reload")
+}
+
+func TestNoHooks(t *testing.T) {
+	t.Parallel()
+
+	var hooks enw.Hooks = enw.NoHooks{}
+
+	hooks.LoadStart(t.Context(), "")
+	hooks.LoadFinish(t.Context(), "", 0, 0, nil)
+	hooks.Find(t.Context(), nil, false)
+	hooks.DefaultApplied(t.Context(), nil)
+	hooks.Reload(t.Context())
+}
+
+func TestFinderHooks(t *testing.T) {
+	t.Parallel()
+
+	hooks := new(recorder)
+	obj, err := enw.NewFinderWithConfig(enw.FinderConfig{Hooks: hooks, Sources: sources()})
+
+	require.NoError(t, err)
+	assert.Equal(t, hooks, obj.Config().Hooks)
+
+	_, err = obj.FindContext(t.Context(), enw.New("VAR_A"))
+
+	require.NoError(t, err)
+
+	_, err = obj.FindContext(t.Context(), &enw.Env{Var: "VAR_D", Tag: enw.Tag{Default: "d"}})
+
+	require.ErrorIs(t, err, enw.ErrEnvNotFound)
+
+	_, err = obj.FindContext(t.Context(), nil)
+
+	require.ErrorIs(t, err, enw.ErrEnvNotFound)
+	require.NoError(t, obj.Reload(t.Context()))
+
+	want := []string{
+		"start memory1", "finish memory1 2 <nil> true",
+		"start memory2", "finish memory2 2 <nil> true",
+		"find VAR_A memory1 true",
+		"find VAR_D  false", "default VAR_D",
+		"reload",
+		"start memory1", "finish memory1 2 <nil> true",
+		"start memory2", "finish memory2 2 <nil> true",
+	}
+
+	assert.Equal(t, want, hooks.events)
+}
+
+func TestComposerHooks(t *testing.T) {
+	t.Parallel()
+
+	hooks := new(recorder)
+	obj, err := enw.NewComposer(enw.Config{
+		Parser:   sethvargo.New(),
+		Target:   struct{}{},
+		Hooks:    hooks,
+		Sources:  []enw.NamedSource{{Name: "memory", Source: memory.New(nil).WithError(enw.ErrEmptyEnvs)}},
+		Autoload: false,
+	})
+
+	require.NoError(t, err)
+	require.ErrorIs(t, obj.Reload(t.Context()), enw.ErrEmptyEnvs)
+
+	want := []string{"reload", "start memory", "finish memory 0 empty envs true"}
+
+	assert.Equal(t, want, hooks.events)
+}
+
+func TestComposerHooksDefault(t *testing.T) {
+	t.Parallel()
+
+	type target struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT,default=80"`
+	}
+
+	hooks := new(recorder)
+	obj, err := enw.NewComposer(enw.Config{
+		Parser:   sethvargo.New(),
+		Target:   target{},
+		Hooks:    hooks,
+		Sources:  []enw.NamedSource{{Name: "memory", Source: memory.New(map[string]string{"HOST": "db"})}},
+		Autoload: true,
+	})
+
+	require.NoError(t, err)
+
+	_, err = obj.FindContext(t.Context(), "PORT")
+
+	require.ErrorIs(t, err, enw.ErrEnvNotFound)
+
+	found := obj.Find("HOST")
+
+	assert.Equal(t, &enw.Env{Var: "HOST", Val: "db", Source: "memory"}, found)
+	assert.Equal(t, []*enw.Env{{Var: "HOST", Val: "db", Source: "memory"}}, obj.Search("HOST"))
+	assert.Nil(t, obj.Find("UNKNOWN"))
+
+	want := []string{
+		"start memory", "finish memory 1 <nil> true",
+		"find PORT  false", "default PORT",
+		"find HOST memory true",
+		"find UNKNOWN  false",
+	}
+
+	assert.Equal(t, want, hooks.events)
+}
@@ -0,0 +1,51 @@
+// Package flatten turns decoded documents into the flat variables, so the
+// sources that read JSON or YAML agree on how nested keys are named.
+package flatten
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const separator = "_"
+
+// Map joins the nested keys with the separator, lists are kept as JSON.
+func Map(data map[string]any, separator string) map[string]string {
+	envs := make(map[string]string)
+
+	walk(envs, data, "", separator)
+
+	return envs
+}
+
+func walk(envs map[string]string, data map[string]any, prefix string, separator string) {
+	for key, val := range data {
+		key = prefix + key
+
+		switch typed := val.(type) {
+		case map[string]any:
+			walk(envs, typed, key+separator, separator)
+		case string:
+			envs[key] = typed
+		case nil:
+			envs[key] = ""
+		case []any:
+			text, err := json.Marshal(typed)
+			if err != nil {
+				text = fmt.Append(nil, typed)
+			}
+
+			envs[key] = string(text)
+		default:
+			envs[key] = fmt.Sprint(typed)
+		}
+	}
+}
+
+// Key turns the path like `db/host-name` into the variable `DB_HOST_NAME`.
+func Key(path string) string {
+	path = strings.Trim(path, "/")
+
+	return strings.ToUpper(strings.NewReplacer("/", separator, "-", separator, ".", separator).Replace(path))
+}
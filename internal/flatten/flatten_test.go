@@ -0,0 +1,48 @@
+package flatten_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/therenotomorrow/enw/internal/flatten"
+)
+
+func TestMap(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"HOST":  "localhost",
+		"PORT":  float64(5432),
+		"BIG":   json.Number("12345678901234567890"),
+		"DEBUG": true,
+		"EMPTY": nil,
+		"LIST":  []any{"a", float64(1)},
+		"DB": map[string]any{
+			"USER":  "admin",
+			"CREDS": map[string]any{"PASS": "secret"},
+		},
+	}
+
+	want := map[string]string{
+		"HOST":          "localhost",
+		"PORT":          "5432",
+		"BIG":           "12345678901234567890",
+		"DEBUG":         "true",
+		"EMPTY":         "",
+		"LIST":          `["a",1]`,
+		"DB_USER":       "admin",
+		"DB_CREDS_PASS": "secret",
+	}
+
+	assert.Equal(t, want, flatten.Map(data, "_"))
+	assert.Equal(t, map[string]string{"a.b": "c"}, flatten.Map(map[string]any{"a": map[string]any{"b": "c"}}, "."))
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "DB_HOST", flatten.Key("db/host"))
+	assert.Equal(t, "DB_HOST_NAME", flatten.Key("/db/host-name/"))
+	assert.Equal(t, "API_V1_URL", flatten.Key("api.v1/url"))
+}
@@ -0,0 +1,138 @@
+// Package static collects the variables from the type-checked sources, so
+// the tools see the same inventory as `enw.CollectType` without running the code.
+package static
+
+import (
+	"cmp"
+	"go/types"
+	"reflect"
+	"slices"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+	"github.com/therenotomorrow/ex"
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	ErrLoadPackage  ex.Const = "cannot load package"
+	ErrTypeNotFound ex.Const = "type not found"
+	ErrNotStruct    ex.Const = "type is not a struct"
+)
+
+func Load(dir string, typeName string) (*types.Package, *types.TypeName, error) {
+	// dependencies are type-checked from sources, so the tools do not
+	// depend on the export data format of the installed toolchain
+	mode := packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps
+	config := &packages.Config{Mode: mode, Dir: dir} //nolint:exhaustruct // too many options
+
+	pkgs, err := packages.Load(config, ".")
+	if err != nil {
+		return nil, nil, ErrLoadPackage.Because(err)
+	}
+
+	if len(pkgs) != 1 || pkgs[0].Types == nil {
+		return nil, nil, ErrLoadPackage.Reason(dir)
+	}
+
+	obj, ok := pkgs[0].Types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, nil, ErrTypeNotFound.Reason(typeName)
+	}
+
+	if _, ok = obj.Type().Underlying().(*types.Struct); !ok {
+		return nil, nil, ErrNotStruct.Reason(typeName)
+	}
+
+	return pkgs[0].Types, obj, nil
+}
+
+// Parse reads the field the same way `sethvargo.Parser.Parse` does.
+func Parse(rStruct *types.Struct, i int, tagKey string) (*enw.Env, string) {
+	field := rStruct.Field(i)
+	tagVal := reflect.StructTag(rStruct.Tag(i)).Get(tagKey)
+
+	if tagVal == "" || tagVal == "-" {
+		return nil, ""
+	}
+
+	value, prefix, tag, _ := sethvargo.ParseTag(tagVal)
+	if value == "" {
+		return nil, prefix
+	}
+
+	return &enw.Env{Var: value, Field: field.Name(), Type: TypeName(field.Type()), Tag: tag}, prefix
+}
+
+// Manifest walks the type the same way `enw.CollectType` does.
+func Manifest(obj *types.TypeName, tagKey string) []*enw.Env {
+	manifest := walk(obj.Type(), tagKey, "", obj.Name(), nil, make([]*enw.Env, 0))
+
+	slices.SortStableFunc(manifest, func(a, b *enw.Env) int {
+		return cmp.Compare(a.Var, b.Var)
+	})
+
+	return manifest
+}
+
+func PkgPath(rType types.Type) string {
+	named, ok := types.Unalias(rType).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+
+	return named.Obj().Pkg().Path()
+}
+
+func walk(
+	rType types.Type, tagKey string, prefix string, path string, visiting []types.Type, envs []*enw.Env,
+) []*enw.Env {
+	rType = types.Unalias(rType)
+
+	for {
+		pointer, ok := rType.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+
+		rType = types.Unalias(pointer.Elem())
+	}
+
+	rStruct, ok := rType.Underlying().(*types.Struct)
+	if !ok || slices.ContainsFunc(visiting, func(visited types.Type) bool { return types.Identical(visited, rType) }) {
+		return envs
+	}
+
+	visiting = append(visiting, rType)
+
+	for i := range rStruct.NumFields() {
+		field := rStruct.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		fieldPath := path + "->" + field.Name()
+
+		env, nested := Parse(rStruct, i, tagKey)
+		if env != nil {
+			env.Var = prefix + env.Var
+			env.Path = fieldPath
+			env.Package = PkgPath(rType)
+
+			envs = append(envs, env)
+		}
+
+		switch typ := field.Type().Underlying().(type) {
+		case *types.Slice:
+			envs = walk(typ.Elem(), tagKey, prefix+nested, fieldPath+"->"+enw.Placeholder, visiting, envs)
+		case *types.Array:
+			envs = walk(typ.Elem(), tagKey, prefix+nested, fieldPath+"->"+enw.Placeholder, visiting, envs)
+		case *types.Map:
+			envs = walk(typ.Elem(), tagKey, prefix+nested, fieldPath+"->"+enw.Placeholder, visiting, envs)
+		default:
+			envs = walk(field.Type(), tagKey, prefix+nested, fieldPath, visiting, envs)
+		}
+	}
+
+	return envs
+}
@@ -0,0 +1,141 @@
+package static
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// TypeName mirrors the `Env.Type` that `parsers/sethvargo` builds from `reflect.Type`.
+func TypeName(rType types.Type) string {
+	switch typ := types.Unalias(rType).(type) {
+	case *types.Named:
+		obj := typ.Obj()
+		if obj.Pkg() == nil {
+			return obj.Name()
+		}
+
+		return obj.Pkg().Path() + "." + obj.Name()
+	case *types.Basic:
+		if typ.Kind() == types.UnsafePointer {
+			return "unsafe.Pointer"
+		}
+
+		return types.Typ[typ.Kind()].Name()
+	default:
+		return reflectString(typ)
+	}
+}
+
+// reflectString mirrors the `reflect.Type.String` format.
+func reflectString(rType types.Type) string { //nolint:cyclop // one branch per kind of type
+	switch typ := types.Unalias(rType).(type) {
+	case *types.Named:
+		obj := typ.Obj()
+		if obj.Pkg() == nil {
+			return obj.Name()
+		}
+
+		return obj.Pkg().Name() + "." + obj.Name()
+	case *types.Basic:
+		return TypeName(typ)
+	case *types.Pointer:
+		return "*" + reflectString(typ.Elem())
+	case *types.Slice:
+		return "[]" + reflectString(typ.Elem())
+	case *types.Array:
+		return "[" + strconv.FormatInt(typ.Len(), 10) + "]" + reflectString(typ.Elem())
+	case *types.Map:
+		return "map[" + reflectString(typ.Key()) + "]" + reflectString(typ.Elem())
+	case *types.Chan:
+		return chanString(typ)
+	case *types.Signature:
+		return "func" + signatureString(typ)
+	case *types.Interface:
+		return interfaceString(typ)
+	case *types.Struct:
+		return structString(typ)
+	default:
+		return typ.String()
+	}
+}
+
+func chanString(typ *types.Chan) string {
+	switch typ.Dir() {
+	case types.SendOnly:
+		return "chan<- " + reflectString(typ.Elem())
+	case types.RecvOnly:
+		return "<-chan " + reflectString(typ.Elem())
+	default:
+		return "chan " + reflectString(typ.Elem())
+	}
+}
+
+func signatureString(typ *types.Signature) string {
+	params := make([]string, 0, typ.Params().Len())
+
+	for i := range typ.Params().Len() {
+		param := reflectString(typ.Params().At(i).Type())
+
+		if typ.Variadic() && i == typ.Params().Len()-1 {
+			param = "..." + strings.TrimPrefix(param, "[]")
+		}
+
+		params = append(params, param)
+	}
+
+	results := make([]string, 0, typ.Results().Len())
+	for i := range typ.Results().Len() {
+		results = append(results, reflectString(typ.Results().At(i).Type()))
+	}
+
+	text := "(" + strings.Join(params, ", ") + ")"
+
+	switch len(results) {
+	case 0:
+		return text
+	case 1:
+		return text + " " + results[0]
+	default:
+		return text + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+func interfaceString(typ *types.Interface) string {
+	if typ.NumMethods() == 0 {
+		return "interface {}"
+	}
+
+	methods := make([]string, 0, typ.NumMethods())
+	for i := range typ.NumMethods() {
+		method := typ.Method(i)
+		methods = append(methods, method.Name()+signatureString(method.Signature()))
+	}
+
+	return "interface { " + strings.Join(methods, "; ") + " }"
+}
+
+func structString(typ *types.Struct) string {
+	if typ.NumFields() == 0 {
+		return "struct {}"
+	}
+
+	fields := make([]string, 0, typ.NumFields())
+
+	for i := range typ.NumFields() {
+		field := typ.Field(i)
+
+		text := reflectString(field.Type())
+		if !field.Embedded() {
+			text = field.Name() + " " + text
+		}
+
+		if tag := typ.Tag(i); tag != "" {
+			text += " " + strconv.Quote(tag)
+		}
+
+		fields = append(fields, text)
+	}
+
+	return "struct { " + strings.Join(fields, "; ") + " }"
+}
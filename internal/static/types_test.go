@@ -0,0 +1,79 @@
+package static_test
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/therenotomorrow/enw/internal/static"
+)
+
+func TestTypeName(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Named", nil), types.Typ[types.Int], nil)
+	str := types.Typ[types.String]
+	tag := `env:"ON"`
+	fields := []*types.Var{types.NewField(token.NoPos, nil, "On", types.Typ[types.Bool], false)}
+	params := types.NewTuple(types.NewParam(token.NoPos, nil, "", types.NewSlice(str)))
+	results := types.NewTuple(types.NewParam(token.NoPos, nil, "", types.Universe.Lookup("error").Type()))
+
+	tests := []struct {
+		rType types.Type
+		name  string
+		want  string
+	}{
+		{name: "basic", rType: types.Typ[types.Int64], want: reflect.TypeFor[int64]().Name()},
+		{name: "byte", rType: types.Universe.Lookup("byte").Type(), want: reflect.TypeFor[byte]().Name()},
+		{name: "named", rType: named, want: "example.com/pkg.Named"},
+		{name: "error", rType: types.Universe.Lookup("error").Type(), want: "error"},
+		{name: "unsafe", rType: types.Typ[types.UnsafePointer], want: "unsafe.Pointer"},
+		{name: "pointer", rType: types.NewPointer(named), want: "*pkg.Named"},
+		{name: "slice", rType: types.NewSlice(str), want: reflect.TypeFor[[]string]().String()},
+		{name: "array", rType: types.NewArray(str, 2), want: reflect.TypeFor[[2]string]().String()},
+		{name: "map", rType: types.NewMap(str, str), want: reflect.TypeFor[map[string]string]().String()},
+		{name: "chan", rType: types.NewChan(types.SendRecv, str), want: reflect.TypeFor[chan string]().String()},
+		{name: "send chan", rType: types.NewChan(types.SendOnly, str), want: reflect.TypeFor[chan<- string]().String()},
+		{name: "recv chan", rType: types.NewChan(types.RecvOnly, str), want: reflect.TypeFor[<-chan string]().String()},
+		{name: "any", rType: types.NewInterfaceType(nil, nil), want: reflect.TypeFor[any]().String()},
+		{
+			name:  "interface",
+			rType: types.Universe.Lookup("error").Type().Underlying(),
+			want:  reflect.TypeFor[interface{ Error() string }]().String(),
+		},
+		{
+			name:  "func",
+			rType: types.NewSignatureType(nil, nil, nil, params, results, true),
+			want:  reflect.TypeFor[func(...string) error]().String(),
+		},
+		{
+			name:  "func without results",
+			rType: types.NewSignatureType(nil, nil, nil, params, nil, false),
+			want:  reflect.TypeFor[func([]string)]().String(),
+		},
+		{
+			name:  "func with results",
+			rType: types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(results.At(0), results.At(0)), false),
+			want:  reflect.TypeFor[func() (error, error)]().String(),
+		},
+		{
+			name:  "struct",
+			rType: types.NewStruct(fields, []string{tag}),
+			want: reflect.TypeFor[struct {
+				On bool `env:"ON"`
+			}]().String(),
+		},
+		{name: "empty struct", rType: types.NewStruct(nil, nil), want: reflect.TypeFor[struct{}]().String()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, static.TypeName(test.rType))
+		})
+	}
+}
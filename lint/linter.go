@@ -0,0 +1,118 @@
+package lint
+
+import (
+	"cmp"
+	"errors"
+	"regexp"
+	"slices"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	ErrInvalidMaxLength ex.Const = "invalid max length, must not be negative"
+	ErrNilRule          ex.Const = "nil rule"
+	ErrViolation        ex.Const = "naming violation"
+)
+
+type (
+	Config struct {
+		Pattern          *regexp.Regexp
+		Prefix           string
+		Reserved         []string
+		Rules            []Rule
+		MaxLength        int
+		ConsistentPrefix bool
+	}
+
+	Linter struct {
+		rules  []Rule
+		config Config
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.MaxLength < 0 {
+		return ErrInvalidMaxLength
+	}
+
+	if slices.Contains(c.Rules, nil) {
+		return ErrNilRule
+	}
+
+	return nil
+}
+
+func New() *Linter {
+	return ex.Must(NewWithConfig(Config{
+		Pattern:          UpperSnake(),
+		Prefix:           "",
+		Reserved:         DefaultReserved(),
+		Rules:            nil,
+		MaxLength:        0,
+		ConsistentPrefix: true,
+	}))
+}
+
+func NewWithConfig(config Config) (*Linter, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0)
+
+	if config.Pattern != nil {
+		rules = append(rules, Pattern{Regexp: config.Pattern})
+	}
+
+	if config.Prefix != "" {
+		rules = append(rules, Prefix{Prefix: config.Prefix})
+	}
+
+	if config.MaxLength != 0 {
+		rules = append(rules, MaxLength{Max: config.MaxLength})
+	}
+
+	if len(config.Reserved) != 0 {
+		rules = append(rules, Reserved{Names: config.Reserved})
+	}
+
+	if config.ConsistentPrefix {
+		rules = append(rules, ConsistentPrefix{})
+	}
+
+	rules = append(rules, config.Rules...)
+
+	return &Linter{rules: rules, config: config}, nil
+}
+
+func (l *Linter) Config() Config {
+	return l.config
+}
+
+func (l *Linter) Lint(envs []*enw.Env) []Violation {
+	envs = slices.DeleteFunc(slices.Clone(envs), func(env *enw.Env) bool { return env == nil })
+	violations := make([]Violation, 0)
+
+	for _, rule := range l.rules {
+		violations = append(violations, rule.Check(envs)...)
+	}
+
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Kind, b.Kind))
+	})
+
+	return violations
+}
+
+func (l *Linter) Check(envs []*enw.Env) error {
+	errs := make([]error, 0)
+
+	for _, found := range l.Lint(envs) {
+		errs = append(errs, ErrViolation.Reason(found.String()))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,176 @@
+package lint_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/lint"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+)
+
+type (
+	database struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+		User string `env:"USER"`
+	}
+
+	server struct {
+		Host  string `env:"SERVER_HOST"`
+		Port  int    `env:"SERVER_PORT"`
+		Debug bool   `env:"DEBUG"`
+	}
+
+	config struct {
+		Server   server
+		Database database `env:",prefix=DB_"`
+		Cache    database
+		Name     string `env:"app-name"`
+		Path     string `env:"PATH"`
+		Pods     string `env:"KUBERNETES_PODS"`
+	}
+)
+
+type upperRule struct{}
+
+func (upperRule) Check(envs []*enw.Env) []lint.Violation {
+	return []lint.Violation{{Var: envs[0].Var, Path: envs[0].Path, Kind: "custom", Message: "custom"}}
+}
+
+func collect(t *testing.T) []*enw.Env {
+	t.Helper()
+
+	collector, err := enw.NewCollector(sethvargo.New())
+
+	require.NoError(t, err)
+
+	envs, err := collector.Collect(config{})
+
+	require.NoError(t, err)
+
+	return envs
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj := lint.New()
+
+	assert.Equal(t, lint.UpperSnake().String(), obj.Config().Pattern.String())
+	assert.Equal(t, lint.DefaultReserved(), obj.Config().Reserved)
+	assert.True(t, obj.Config().ConsistentPrefix)
+}
+
+func TestNewWithConfig(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		config lint.Config
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want error
+	}{
+		{name: "empty", args: args{config: lint.Config{}}, want: nil},
+		{name: "max length", args: args{config: lint.Config{MaxLength: -1}}, want: lint.ErrInvalidMaxLength},
+		{name: "nil rule", args: args{config: lint.Config{Rules: []lint.Rule{nil}}}, want: lint.ErrNilRule},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := lint.NewWithConfig(test.args.config)
+
+			require.ErrorIs(t, err, test.want)
+
+			if test.want != nil {
+				assert.Nil(t, obj)
+			}
+		})
+	}
+}
+
+func TestLinterLint(t *testing.T) {
+	t.Parallel()
+
+	got := lint.New().Lint(collect(t))
+	want := []lint.Violation{
+		{
+			Var:     "USER",
+			Path:    "config->Cache->User",
+			Kind:    lint.KindReserved,
+			Message: "USER shadows reserved USER",
+		},
+		{
+			Var:     "app-name",
+			Path:    "config->Name",
+			Kind:    lint.KindPattern,
+			Message: "app-name does not match " + lint.UpperSnake().String(),
+		},
+		{
+			Var:     "PATH",
+			Path:    "config->Path",
+			Kind:    lint.KindReserved,
+			Message: "PATH shadows reserved PATH",
+		},
+		{
+			Var:     "KUBERNETES_PODS",
+			Path:    "config->Pods",
+			Kind:    lint.KindReserved,
+			Message: "KUBERNETES_PODS shadows reserved KUBERNETES_*",
+		},
+		{
+			Var:     "DEBUG",
+			Path:    "config->Server->Debug",
+			Kind:    lint.KindConsistentPrefix,
+			Message: "DEBUG does not share prefix SERVER_ of its siblings at config->Server",
+		},
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestLinterLintConfig(t *testing.T) {
+	t.Parallel()
+
+	obj, err := lint.NewWithConfig(lint.Config{
+		Pattern:          regexp.MustCompile(`^[A-Z_]+$`),
+		Prefix:           "DB_",
+		Reserved:         nil,
+		Rules:            []lint.Rule{upperRule{}},
+		MaxLength:        7,
+		ConsistentPrefix: false,
+	})
+
+	require.NoError(t, err)
+
+	got := obj.Lint([]*enw.Env{nil, {Var: "DB_HOST", Path: "a->Host"}, {Var: "CACHE_HOST1", Path: "a->Cache"}})
+	want := []lint.Violation{
+		{Var: "CACHE_HOST1", Path: "a->Cache", Kind: lint.KindMaxLength, Message: "CACHE_HOST1 is longer than 7"},
+		{Var: "CACHE_HOST1", Path: "a->Cache", Kind: lint.KindPattern, Message: "CACHE_HOST1 does not match ^[A-Z_]+$"},
+		{Var: "CACHE_HOST1", Path: "a->Cache", Kind: lint.KindPrefix, Message: "CACHE_HOST1 does not start with DB_"},
+		{Var: "DB_HOST", Path: "a->Host", Kind: "custom", Message: "custom"},
+	}
+
+	assert.Equal(t, want, got)
+}
+
+func TestLinterCheck(t *testing.T) {
+	t.Parallel()
+
+	obj := lint.New()
+
+	require.NoError(t, obj.Check([]*enw.Env{{Var: "DB_HOST", Path: "a->Host"}}))
+
+	err := obj.Check([]*enw.Env{{Var: "db_host", Path: "a->Host"}, {Var: "HOME", Path: "a->Home"}})
+
+	require.ErrorIs(t, err, lint.ErrViolation)
+	assert.ErrorContains(t, err, "naming violation: a->Home: reserved: HOME shadows reserved HOME")
+	assert.ErrorContains(t, err, "naming violation: a->Host: pattern: db_host does not match")
+}
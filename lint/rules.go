@@ -0,0 +1,187 @@
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/therenotomorrow/enw"
+)
+
+const (
+	KindPattern          Kind = "pattern"
+	KindPrefix           Kind = "prefix"
+	KindMaxLength        Kind = "max length"
+	KindReserved         Kind = "reserved"
+	KindConsistentPrefix Kind = "consistent prefix"
+
+	wildcard  = "*"
+	separator = "_"
+	pathSplit = "->"
+)
+
+type (
+	Kind string
+
+	Rule interface {
+		Check(envs []*enw.Env) []Violation
+	}
+
+	Violation struct {
+		Var     string
+		Path    string
+		Kind    Kind
+		Message string
+	}
+
+	Pattern struct {
+		Regexp *regexp.Regexp
+	}
+
+	Prefix struct {
+		Prefix string
+	}
+
+	MaxLength struct {
+		Max int
+	}
+
+	Reserved struct {
+		Names []string
+	}
+
+	ConsistentPrefix struct{}
+)
+
+func (v Violation) String() string {
+	return v.Path + ": " + string(v.Kind) + ": " + v.Message
+}
+
+// UpperSnake matches names like `DB_HOST` or `HTTP2_PORT`.
+func UpperSnake() *regexp.Regexp {
+	return regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+}
+
+// DefaultReserved lists the names that the shell or the orchestrator sets
+// on its own, a trailing `*` reserves the whole prefix.
+func DefaultReserved() []string {
+	return []string{
+		"HOME", "HOSTNAME", "LANG", "PATH", "PWD", "SHELL", "TERM", "TMPDIR", "TZ", "USER",
+		"KUBERNETES_*",
+	}
+}
+
+func (r Pattern) Check(envs []*enw.Env) []Violation {
+	return each(envs, func(env *enw.Env) (Violation, bool) {
+		return violation(env, KindPattern, "%s does not match %s", env.Var, r.Regexp), !r.Regexp.MatchString(env.Var)
+	})
+}
+
+func (r Prefix) Check(envs []*enw.Env) []Violation {
+	return each(envs, func(env *enw.Env) (Violation, bool) {
+		return violation(env, KindPrefix, "%s does not start with %s", env.Var, r.Prefix),
+			!strings.HasPrefix(env.Var, r.Prefix)
+	})
+}
+
+func (r MaxLength) Check(envs []*enw.Env) []Violation {
+	return each(envs, func(env *enw.Env) (Violation, bool) {
+		return violation(env, KindMaxLength, "%s is longer than %d", env.Var, r.Max), len(env.Var) > r.Max
+	})
+}
+
+func (r Reserved) Check(envs []*enw.Env) []Violation {
+	return each(envs, func(env *enw.Env) (Violation, bool) {
+		for _, name := range r.Names {
+			prefix, ok := strings.CutSuffix(name, wildcard)
+			if (ok && strings.HasPrefix(env.Var, prefix)) || env.Var == name {
+				return violation(env, KindReserved, "%s shadows reserved %s", env.Var, name), true
+			}
+		}
+
+		return Violation{}, false
+	})
+}
+
+// Check groups variables by the struct they are declared in and reports the
+// ones that do not share the leading segment most of their siblings use.
+func (r ConsistentPrefix) Check(envs []*enw.Env) []Violation {
+	groups := make(map[string][]*enw.Env)
+
+	for _, env := range envs {
+		parent, _, ok := cutLast(env.Path, pathSplit)
+		if ok && strings.Contains(parent, pathSplit) {
+			groups[parent] = append(groups[parent], env)
+		}
+	}
+
+	violations := make([]Violation, 0)
+
+	for parent, group := range groups {
+		common := majority(group)
+
+		for _, env := range group {
+			if segment(env.Var) != common {
+				violations = append(violations, violation(env, KindConsistentPrefix,
+					"%s does not share prefix %s of its siblings at %s", env.Var, common, parent))
+			}
+		}
+	}
+
+	return violations
+}
+
+func each(envs []*enw.Env, check func(env *enw.Env) (Violation, bool)) []Violation {
+	violations := make([]Violation, 0)
+
+	for _, env := range envs {
+		if found, ok := check(env); ok {
+			violations = append(violations, found)
+		}
+	}
+
+	return violations
+}
+
+func violation(env *enw.Env, kind Kind, format string, args ...any) Violation {
+	return Violation{Var: env.Var, Path: env.Path, Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+func cutLast(text string, sep string) (string, string, bool) {
+	i := strings.LastIndex(text, sep)
+	if i < 0 {
+		return text, "", false
+	}
+
+	return text[:i], text[i+len(sep):], true
+}
+
+func segment(name string) string {
+	head, _, ok := strings.Cut(name, separator)
+	if !ok {
+		return ""
+	}
+
+	return head + separator
+}
+
+func majority(group []*enw.Env) string {
+	counts := make(map[string]int)
+	for _, env := range group {
+		counts[segment(env.Var)]++
+	}
+
+	segments := make([]string, 0, len(counts))
+	for seg := range counts {
+		segments = append(segments, seg)
+	}
+
+	// ties are broken by name to keep the report stable
+	slices.SortFunc(segments, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+
+	return segments[0]
+}
@@ -0,0 +1,70 @@
+package lint_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/lint"
+)
+
+func TestUpperSnake(t *testing.T) {
+	t.Parallel()
+
+	re := lint.UpperSnake()
+
+	for _, name := range []string{"HOST", "DB_HOST", "HTTP2_PORT", "A_1"} {
+		assert.True(t, re.MatchString(name), name)
+	}
+
+	for _, name := range []string{"host", "Db_HOST", "_HOST", "HOST_", "DB__HOST", "1HOST", "DB-HOST"} {
+		assert.False(t, re.MatchString(name), name)
+	}
+}
+
+func TestRulesCheck(t *testing.T) {
+	t.Parallel()
+
+	envs := []*enw.Env{
+		{Var: "KUBERNETES", Path: "a->Kube"},
+		{Var: "KUBERNETES_PORT", Path: "a->Port"},
+		{Var: "DB_HOST", Path: "a->Db->Host"},
+		{Var: "DB_PORT", Path: "a->Db->Port"},
+		{Var: "CACHE_USER", Path: "a->Db->User"},
+		{Var: "DB_ONLY", Path: "a->Db->Inner->Only"},
+		{Var: "A_X", Path: "a->Tie->X"},
+		{Var: "B_Y", Path: "a->Tie->Y"},
+	}
+
+	tests := []struct {
+		rule lint.Rule
+		name string
+		want []string
+	}{
+		{name: "pattern", rule: lint.Pattern{Regexp: regexp.MustCompile(`^K`)}, want: []string{
+			"DB_HOST", "DB_PORT", "CACHE_USER", "DB_ONLY", "A_X", "B_Y",
+		}},
+		{name: "prefix", rule: lint.Prefix{Prefix: "DB_"}, want: []string{
+			"KUBERNETES", "KUBERNETES_PORT", "CACHE_USER", "A_X", "B_Y",
+		}},
+		{name: "max length", rule: lint.MaxLength{Max: 7}, want: []string{
+			"KUBERNETES", "KUBERNETES_PORT", "CACHE_USER",
+		}},
+		{name: "reserved", rule: lint.Reserved{Names: []string{"KUBERNETES_*"}}, want: []string{"KUBERNETES_PORT"}},
+		{name: "consistent prefix", rule: lint.ConsistentPrefix{}, want: []string{"CACHE_USER", "B_Y"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := make([]string, 0)
+			for _, violation := range test.rule.Check(envs) {
+				got = append(got, violation.Var)
+			}
+
+			assert.ElementsMatch(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package observe
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/therenotomorrow/enw"
+)
+
+type Logger struct {
+	logger *slog.Logger
+}
+
+func NewLogger(logger *slog.Logger) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Logger{logger: logger}
+}
+
+func (l *Logger) LoadStart(ctx context.Context, source string) {
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "source loading", slog.String("source", source))
+}
+
+func (l *Logger) LoadFinish(ctx context.Context, source string, duration time.Duration, keys int, err error) {
+	attrs := []slog.Attr{slog.String("source", source), slog.Duration("duration", duration), slog.Int("keys", keys)}
+
+	if err != nil {
+		l.logger.LogAttrs(ctx, slog.LevelError, "source failed", append(attrs, slog.Any("error", err))...)
+
+		return
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "source loaded", attrs...)
+}
+
+func (l *Logger) Find(ctx context.Context, env *enw.Env, hit bool) {
+	if hit {
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "env found",
+			slog.String("var", env.Var), slog.String("source", env.Source))
+
+		return
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "env not found", slog.String("var", env.Var))
+}
+
+func (l *Logger) DefaultApplied(ctx context.Context, env *enw.Env) {
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "env default applied", slog.String("var", env.Var))
+}
+
+func (l *Logger) Reload(ctx context.Context) {
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "sources reloading")
+}
@@ -0,0 +1,50 @@
+package observe_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/observe"
+)
+
+func TestLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: noTime})
+	obj := observe.NewLogger(slog.New(handler))
+
+	obj.LoadStart(t.Context(), "system")
+	obj.LoadFinish(t.Context(), "system", 0, 3, nil)
+	obj.LoadFinish(t.Context(), "k8s", 0, 0, enw.ErrEmptyEnvs)
+	obj.Find(t.Context(), &enw.Env{Var: "HOST", Source: "system"}, true)
+	obj.Find(t.Context(), enw.New("PORT"), false)
+	obj.DefaultApplied(t.Context(), enw.New("PORT"))
+	obj.Reload(t.Context())
+
+	want := []string{
+		`level=DEBUG msg="source loading" source=system`,
+		`level=INFO msg="source loaded" source=system duration=0s keys=3`,
+		`level=ERROR msg="source failed" source=k8s duration=0s keys=0 error="empty envs"`,
+		`level=DEBUG msg="env found" var=HOST source=system`,
+		`level=DEBUG msg="env not found" var=PORT`,
+		`level=DEBUG msg="env default applied" var=PORT`,
+		`level=INFO msg="sources reloading"`,
+	}
+
+	assert.Equal(t, want, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+	assert.NotNil(t, observe.NewLogger(nil))
+}
+
+func noTime(_ []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+
+	return attr
+}
@@ -0,0 +1,73 @@
+package observe
+
+import (
+	"context"
+	"time"
+
+	"github.com/therenotomorrow/enw"
+)
+
+const (
+	MetricLoads        = "enw_source_loads_total"
+	MetricLoadDuration = "enw_source_load_duration_seconds"
+	MetricKeys         = "enw_source_keys"
+	MetricLookups      = "enw_lookups_total"
+	MetricDefaults     = "enw_defaults_applied_total"
+	MetricReloads      = "enw_reloads_total"
+
+	resultOK    = "ok"
+	resultError = "error"
+	resultHit   = "hit"
+	resultMiss  = "miss"
+)
+
+type Metrics struct {
+	registry *Registry
+}
+
+func NewMetrics(registry *Registry) *Metrics {
+	if registry == nil {
+		registry = NewRegistry()
+	}
+
+	return &Metrics{registry: registry}
+}
+
+func (m *Metrics) Registry() *Registry {
+	return m.registry
+}
+
+func (m *Metrics) LoadStart(context.Context, string) {}
+
+func (m *Metrics) LoadFinish(_ context.Context, source string, duration time.Duration, keys int, err error) {
+	result := resultOK
+	if err != nil {
+		result = resultError
+	}
+
+	m.registry.Add(MetricLoads, "Source extractions by result.", Labels{"source": source, "result": result}, 1)
+	m.registry.Set(MetricLoadDuration, "Duration of the last source extraction.", Labels{"source": source},
+		duration.Seconds())
+
+	if err == nil {
+		m.registry.Set(MetricKeys, "Keys extracted by the last source extraction.", Labels{"source": source},
+			float64(keys))
+	}
+}
+
+func (m *Metrics) Find(_ context.Context, env *enw.Env, hit bool) {
+	labels := Labels{"result": resultMiss, "source": ""}
+	if hit {
+		labels = Labels{"result": resultHit, "source": env.Source}
+	}
+
+	m.registry.Add(MetricLookups, "Lookups by result and winning source.", labels, 1)
+}
+
+func (m *Metrics) DefaultApplied(context.Context, *enw.Env) {
+	m.registry.Add(MetricDefaults, "Lookups that fall back to the default.", nil, 1)
+}
+
+func (m *Metrics) Reload(context.Context) {
+	m.registry.Add(MetricReloads, "Reloads of the sources.", nil, 1)
+}
@@ -0,0 +1,54 @@
+package observe_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/observe"
+	"github.com/therenotomorrow/enw/sources/memory"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := observe.NewMetrics(nil)
+	hooks := observe.Multi{metrics, observe.NewLogger(slog.New(slog.NewTextHandler(new(bytes.Buffer), nil)))}
+
+	finder, err := enw.NewFinderWithConfig(enw.FinderConfig{
+		Hooks: hooks,
+		Sources: []enw.NamedSource{
+			{Name: "memory", Source: memory.New(map[string]string{"HOST": "localhost", "PORT": "80"})},
+		},
+	})
+
+	require.NoError(t, err)
+
+	_, err = finder.FindContext(t.Context(), enw.New("HOST"))
+
+	require.NoError(t, err)
+
+	_, err = finder.FindContext(t.Context(), &enw.Env{Var: "USER", Tag: enw.Tag{Default: "root"}})
+
+	require.ErrorIs(t, err, enw.ErrEnvNotFound)
+	require.NoError(t, finder.Reload(t.Context()))
+
+	registry := metrics.Registry()
+	hit := observe.Labels{"result": "hit", "source": "memory"}
+	failed := observe.Labels{"source": "memory", "result": "error"}
+
+	assert.InDelta(t, 2.0, registry.Value(observe.MetricLoads, observe.Labels{"source": "memory", "result": "ok"}), 0)
+	assert.InDelta(t, 2.0, registry.Value(observe.MetricKeys, observe.Labels{"source": "memory"}), 0)
+	assert.InDelta(t, 1.0, registry.Value(observe.MetricLookups, hit), 0)
+	assert.InDelta(t, 1.0, registry.Value(observe.MetricLookups, observe.Labels{"result": "miss", "source": ""}), 0)
+	assert.InDelta(t, 1.0, registry.Value(observe.MetricDefaults, nil), 0)
+	assert.InDelta(t, 1.0, registry.Value(observe.MetricReloads, nil), 0)
+
+	metrics.LoadFinish(t.Context(), "memory", 0, 0, enw.ErrEmptyEnvs)
+
+	assert.InDelta(t, 1.0, registry.Value(observe.MetricLoads, failed), 0)
+	assert.InDelta(t, 2.0, registry.Value(observe.MetricKeys, observe.Labels{"source": "memory"}), 0)
+}
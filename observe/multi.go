@@ -0,0 +1,40 @@
+package observe
+
+import (
+	"context"
+	"time"
+
+	"github.com/therenotomorrow/enw"
+)
+
+type Multi []enw.Hooks
+
+func (m Multi) LoadStart(ctx context.Context, source string) {
+	for _, hooks := range m {
+		hooks.LoadStart(ctx, source)
+	}
+}
+
+func (m Multi) LoadFinish(ctx context.Context, source string, duration time.Duration, keys int, err error) {
+	for _, hooks := range m {
+		hooks.LoadFinish(ctx, source, duration, keys, err)
+	}
+}
+
+func (m Multi) Find(ctx context.Context, env *enw.Env, hit bool) {
+	for _, hooks := range m {
+		hooks.Find(ctx, env, hit)
+	}
+}
+
+func (m Multi) DefaultApplied(ctx context.Context, env *enw.Env) {
+	for _, hooks := range m {
+		hooks.DefaultApplied(ctx, env)
+	}
+}
+
+func (m Multi) Reload(ctx context.Context) {
+	for _, hooks := range m {
+		hooks.Reload(ctx)
+	}
+}
@@ -0,0 +1,113 @@
+package observe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	counter = "counter"
+	gauge   = "gauge"
+)
+
+type (
+	Labels map[string]string
+
+	// Registry keeps the metrics in memory and writes them in the
+	// Prometheus text exposition format.
+	Registry struct {
+		families map[string]*family
+		mutex    sync.Mutex
+	}
+
+	family struct {
+		samples map[string]float64
+		kind    string
+		help    string
+	}
+)
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family), mutex: sync.Mutex{}}
+}
+
+func (r *Registry) Add(name string, help string, labels Labels, value float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.family(name, help, counter).samples[labels.String()] += value
+}
+
+func (r *Registry) Set(name string, help string, labels Labels, value float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.family(name, help, gauge).samples[labels.String()] = value
+}
+
+func (r *Registry) Value(name string, labels Labels) float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if found, ok := r.families[name]; ok {
+		return found.samples[labels.String()]
+	}
+
+	return 0
+}
+
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mutex.Lock()
+
+	var buf bytes.Buffer
+
+	for _, name := range slices.Sorted(maps.Keys(r.families)) {
+		found := r.families[name]
+
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", name, found.help, name, found.kind)
+
+		for _, labels := range slices.Sorted(maps.Keys(found.samples)) {
+			fmt.Fprintf(&buf, "%s%s %s\n", name, labels, strconv.FormatFloat(found.samples[labels], 'g', -1, 64))
+		}
+	}
+
+	r.mutex.Unlock()
+
+	return buf.WriteTo(w)
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, _ = r.WriteTo(w)
+}
+
+func (r *Registry) family(name string, help string, kind string) *family {
+	found, ok := r.families[name]
+	if !ok {
+		found = &family{samples: make(map[string]float64), kind: kind, help: help}
+		r.families[name] = found
+	}
+
+	return found
+}
+
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(l))
+	for _, name := range slices.Sorted(maps.Keys(l)) {
+		pairs = append(pairs, name+"="+strconv.Quote(l[name]))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
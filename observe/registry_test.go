@@ -0,0 +1,41 @@
+package observe_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/therenotomorrow/enw/observe"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	obj := observe.NewRegistry()
+
+	obj.Add("b_total", "Counter.", observe.Labels{"source": "k8s", "result": "ok"}, 1)
+	obj.Add("b_total", "Counter.", observe.Labels{"result": "ok", "source": "k8s"}, 2)
+	obj.Add("b_total", "Counter.", observe.Labels{"source": `a"b`}, 1)
+	obj.Set("a_seconds", "Gauge.", nil, 0.5)
+	obj.Set("a_seconds", "Gauge.", nil, 1.5)
+
+	assert.InDelta(t, 3.0, obj.Value("b_total", observe.Labels{"result": "ok", "source": "k8s"}), 0)
+	assert.InDelta(t, 1.5, obj.Value("a_seconds", nil), 0)
+	assert.InDelta(t, 0.0, obj.Value("missing", nil), 0)
+
+	rec := httptest.NewRecorder()
+	obj.ServeHTTP(rec, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/metrics", nil))
+
+	want := `# HELP a_seconds Gauge.
+# TYPE a_seconds gauge
+a_seconds 1.5
+# HELP b_total Counter.
+# TYPE b_total counter
+b_total{result="ok",source="k8s"} 3
+b_total{source="a\"b"} 1
+`
+
+	assert.Equal(t, want, rec.Body.String())
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+}
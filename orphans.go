@@ -0,0 +1,137 @@
+package enw
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/therenotomorrow/ex"
+)
+
+const maxSuggestionDistance = 2
+
+type Orphan struct {
+	Source      string
+	Key         string
+	Suggestions []string
+}
+
+func (f *Finder) Orphans(envs []*Env) []Orphan {
+	return ex.Must(f.OrphansContext(context.Background(), envs))
+}
+
+// OrphansContext reports the keys that sources define but none of the envs
+// consume, grouped by source in the priority order. Variables referenced by
+// the defaults are consumed too.
+func (f *Finder) OrphansContext(ctx context.Context, envs []*Env) ([]Orphan, error) {
+	storage, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	consumed := make(map[string]bool)
+	names := make([]string, 0, len(envs))
+
+	for _, env := range envs {
+		if env == nil || consumed[env.Var] {
+			continue
+		}
+
+		consumed[env.Var] = true
+		names = append(names, env.Var)
+
+		for _, name := range env.Tag.Expands() {
+			consumed[name] = true
+		}
+	}
+
+	orphans := make([]Orphan, 0)
+
+	for _, source := range f.config.Sources {
+		keys := make([]string, 0)
+
+		for key := range storage[source.Name] {
+			if !consumed[key] {
+				keys = append(keys, key)
+			}
+		}
+
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			orphans = append(orphans, Orphan{Source: source.Name, Key: key, Suggestions: suggestions(key, names)})
+		}
+	}
+
+	return orphans, nil
+}
+
+func (c *Composer) Orphans() ([]Orphan, error) {
+	envs, err := c.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.finder.OrphansContext(context.Background(), envs)
+}
+
+func suggestions(key string, names []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	candidates := make([]candidate, 0)
+
+	for _, name := range names {
+		// short keys are too close to everything to be a typo
+		dist := distance(key, name)
+		if dist <= maxSuggestionDistance && dist < len(key)/2 {
+			candidates = append(candidates, candidate{name: name, distance: dist})
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), cmp.Compare(a.name, b.name))
+	})
+
+	found := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		found = append(found, candidate.name)
+	}
+
+	return found
+}
+
+// distance is the optimal string alignment distance, so a swap of two
+// adjacent letters like `DB_HOTS` and `DB_HOST` costs one edit.
+func distance(a string, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,120 @@
+package enw_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+	"github.com/therenotomorrow/enw/sources/memory"
+)
+
+func TestFinderOrphans(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		envs []*enw.Env
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want []enw.Orphan
+	}{
+		{
+			name: "all orphans",
+			args: args{envs: nil},
+			want: []enw.Orphan{
+				{Source: "memory1", Key: "VAR_A", Suggestions: []string{}},
+				{Source: "memory1", Key: "VAR_B", Suggestions: []string{}},
+				{Source: "memory2", Key: "VAR_A", Suggestions: []string{}},
+				{Source: "memory2", Key: "VAR_C", Suggestions: []string{}},
+			},
+		},
+		{
+			name: "consumed",
+			args: args{envs: []*enw.Env{nil, enw.New("VAR_A"), enw.New("VAR_A")}},
+			want: []enw.Orphan{
+				{Source: "memory1", Key: "VAR_B", Suggestions: []string{"VAR_A"}},
+				{Source: "memory2", Key: "VAR_C", Suggestions: []string{"VAR_A"}},
+			},
+		},
+		{
+			name: "consumed by defaults",
+			args: args{envs: []*enw.Env{{Var: "VAR_D", Tag: enw.Tag{Default: "${VAR_A}-$VAR_B-${VAR_C}"}}}},
+			want: []enw.Orphan{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := enw.NewFinder(sources())
+
+			require.NoError(t, err)
+
+			got := obj.Orphans(test.args.envs)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("panics", func(t *testing.T) {
+		t.Parallel()
+
+		obj, err := enw.NewFinder(
+			[]enw.NamedSource{{Name: "memory", Source: memory.New(nil).WithError(enw.ErrNilTarget)}},
+		)
+
+		require.NoError(t, err)
+		assert.PanicsWithValue(t, enw.ErrNilTarget, func() { _ = obj.Orphans(nil) })
+	})
+}
+
+func TestFinderOrphansContextSuggestions(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]string{"DB_HOTS": "", "DB_PROT": "", "DBHOST": "", "DB_HOST_NAME": "", "ID": "", "TIMEOUT": ""}
+	obj, err := enw.NewFinder([]enw.NamedSource{{Name: "configmap", Source: memory.New(data)}})
+
+	require.NoError(t, err)
+
+	envs := []*enw.Env{enw.New("DB_HOST"), enw.New("DB_PORT"), enw.New("DB_PASS"), enw.New("IP")}
+	got, err := obj.OrphansContext(t.Context(), envs)
+	want := []enw.Orphan{
+		{Source: "configmap", Key: "DBHOST", Suggestions: []string{"DB_HOST"}},
+		{Source: "configmap", Key: "DB_HOST_NAME", Suggestions: []string{}},
+		{Source: "configmap", Key: "DB_HOTS", Suggestions: []string{"DB_HOST"}},
+		{Source: "configmap", Key: "DB_PROT", Suggestions: []string{"DB_PORT"}},
+		{Source: "configmap", Key: "ID", Suggestions: []string{}},
+		{Source: "configmap", Key: "TIMEOUT", Suggestions: []string{}},
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestComposerOrphans(t *testing.T) {
+	t.Parallel()
+
+	type sampleConfig struct {
+		Host string `env:"DB_HOST"`
+	}
+
+	obj, err := enw.NewComposer(enw.Config{
+		Parser:   sethvargo.New(),
+		Sources:  []enw.NamedSource{{Name: "memory", Source: memory.New(map[string]string{"DB_HOTS": "localhost"})}},
+		Target:   sampleConfig{},
+		Autoload: false,
+	})
+
+	require.NoError(t, err)
+
+	got, err := obj.Orphans()
+	want := []enw.Orphan{{Source: "memory", Key: "DB_HOTS", Suggestions: []string{"DB_HOST"}}}
+
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
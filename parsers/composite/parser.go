@@ -0,0 +1,232 @@
+package composite
+
+import (
+	"cmp"
+	"go/token"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	First Policy = "first"
+	Merge Policy = "merge"
+
+	VarConflict     Kind = "var"
+	DefaultConflict Kind = "default"
+	PrefixConflict  Kind = "prefix"
+
+	ErrMissingParsers ex.Const = "missing parsers"
+	ErrInvalidPolicy  ex.Const = "invalid policy"
+)
+
+type (
+	Policy string
+
+	Kind string
+
+	Config struct {
+		Policy  Policy
+		Parsers []enw.Parser
+	}
+
+	Conflict struct {
+		Path   string
+		Field  string
+		Kind   Kind
+		Values []string
+	}
+
+	Parser struct {
+		seen      map[declaration]bool
+		conflicts []Conflict
+		config    Config
+		mutex     sync.Mutex
+	}
+
+	// declaration is the conflicting field at its path without the list
+	// indexes and the map keys, so it is reported once for the schema and the
+	// value collections and for every element.
+	declaration struct {
+		path string
+		kind Kind
+	}
+
+	result struct {
+		env    *enw.Env
+		prefix string
+	}
+)
+
+func (c *Config) Validate() error {
+	if len(c.Parsers) == 0 || slices.Contains(c.Parsers, nil) {
+		return ErrMissingParsers
+	}
+
+	switch c.Policy {
+	case First, Merge:
+	default:
+		return ErrInvalidPolicy
+	}
+
+	return nil
+}
+
+func New(parsers ...enw.Parser) (*Parser, error) {
+	return NewWithConfig(Config{Policy: First, Parsers: parsers})
+}
+
+func NewWithConfig(config Config) (*Parser, error) {
+	if config.Policy == "" {
+		config.Policy = First
+	}
+
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Parser{
+		seen:      make(map[declaration]bool),
+		config:    config,
+		conflicts: make([]Conflict, 0),
+		mutex:     sync.Mutex{},
+	}, nil
+}
+
+func (p *Parser) Config() Config {
+	return p.config
+}
+
+func (p *Parser) Conflicts() []Conflict {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return slices.Clone(p.conflicts)
+}
+
+// Reset forgets the reported conflicts, so the parser can be reused.
+func (p *Parser) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.seen = make(map[declaration]bool)
+	p.conflicts = make([]Conflict, 0)
+}
+
+func (p *Parser) Parse(field *reflect.StructField, path string, pkg string) (*enw.Env, string) {
+	results := make([]result, 0, len(p.config.Parsers))
+
+	for _, parser := range p.config.Parsers {
+		env, prefix := parser.Parse(field, path, pkg)
+		if env != nil || prefix != "" {
+			results = append(results, result{env: env, prefix: prefix})
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, ""
+	}
+
+	p.detect(field, path, results)
+
+	var (
+		winner *enw.Env
+		prefix string
+	)
+
+	// the first dialect may give only the prefix, the name comes from the next
+	if p.config.Policy == First {
+		for _, res := range results {
+			winner = cmp.Or(winner, res.env)
+			prefix = cmp.Or(prefix, res.prefix)
+		}
+
+		return winner, prefix
+	}
+
+	for _, res := range results {
+		prefix = cmp.Or(prefix, res.prefix)
+
+		switch {
+		case res.env == nil:
+		case winner == nil:
+			clone := *res.env
+			winner = &clone
+		default:
+			merge(&winner.Tag, &res.env.Tag)
+		}
+	}
+
+	if winner != nil {
+		winner.Tag.Empty = winner.Tag.Empty && prefix == ""
+	}
+
+	return winner, prefix
+}
+
+func (p *Parser) detect(field *reflect.StructField, path string, results []result) {
+	var vars, defaults, prefixes []string
+
+	for _, res := range results {
+		if res.env != nil {
+			vars = appendUnique(vars, res.env.Var)
+			defaults = appendUnique(defaults, res.env.Tag.Default)
+		}
+
+		prefixes = appendUnique(prefixes, res.prefix)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, conflict := range []Conflict{
+		{Path: path, Field: field.Name, Kind: VarConflict, Values: vars},
+		{Path: path, Field: field.Name, Kind: DefaultConflict, Values: defaults},
+		{Path: path, Field: field.Name, Kind: PrefixConflict, Values: prefixes},
+	} {
+		key := declaration{path: shape(path), kind: conflict.Kind}
+		if len(conflict.Values) > 1 && !p.seen[key] {
+			p.seen[key] = true
+			p.conflicts = append(p.conflicts, conflict)
+		}
+	}
+}
+
+// shape replaces the path parts that are not the field names with the
+// placeholder, the map keys that look like the field names are kept.
+func shape(path string) string {
+	parts := strings.Split(path, "->")
+
+	for i := 1; i < len(parts); i++ {
+		if !token.IsIdentifier(parts[i]) || !token.IsExported(parts[i]) {
+			parts[i] = enw.Placeholder
+		}
+	}
+
+	return strings.Join(parts, "->")
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" || slices.Contains(values, value) {
+		return values
+	}
+
+	return append(values, value)
+}
+
+func merge(dst *enw.Tag, src *enw.Tag) {
+	dst.Default = cmp.Or(dst.Default, src.Default)
+
+	dst.Delimiter = cmp.Or(dst.Delimiter, src.Delimiter)
+	dst.Separator = cmp.Or(dst.Separator, src.Separator)
+	dst.Required = dst.Required || src.Required
+	dst.Overwrite = dst.Overwrite || src.Overwrite
+	dst.NoInit = dst.NoInit || src.NoInit
+	dst.DecodeUnset = dst.DecodeUnset || src.DecodeUnset
+	dst.Empty = dst.Empty && src.Empty
+}
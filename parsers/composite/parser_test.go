@@ -0,0 +1,328 @@
+package composite_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/parsers/composite"
+	"github.com/therenotomorrow/enw/parsers/sethvargo"
+)
+
+func parsers() []enw.Parser {
+	return []enw.Parser{
+		sethvargo.New(),
+		sethvargo.NewWithConfig(sethvargo.Config{TagKey: "envconfig"}),
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := composite.New(parsers()...)
+
+	require.NoError(t, err)
+	assert.Equal(t, composite.First, obj.Config().Policy)
+}
+
+func TestNewWithConfig(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		config composite.Config
+	}
+
+	tests := []struct {
+		err  error
+		name string
+		args args
+	}{
+		{name: "success", args: args{config: composite.Config{Parsers: parsers(), Policy: composite.Merge}}, err: nil},
+		{name: "default policy", args: args{config: composite.Config{Parsers: parsers()}}, err: nil},
+		{name: "missing parsers", args: args{config: composite.Config{}}, err: composite.ErrMissingParsers},
+		{
+			name: "nil parser",
+			args: args{config: composite.Config{Parsers: []enw.Parser{nil}}},
+			err:  composite.ErrMissingParsers,
+		},
+		{
+			name: "invalid policy",
+			args: args{config: composite.Config{Parsers: parsers(), Policy: "invalid"}},
+			err:  composite.ErrInvalidPolicy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := composite.NewWithConfig(test.args.config)
+			if test.err != nil {
+				require.ErrorIs(t, err, test.err)
+				assert.Nil(t, obj)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, obj)
+			}
+		})
+	}
+}
+
+func TestParserParse(t *testing.T) {
+	t.Parallel()
+
+	type sampleStruct struct {
+		OnlyFirst  string `env:"FIRST,required"`
+		OnlySecond string `envconfig:"SECOND,default=second"`
+		Both       string `env:"BOTH,required"                envconfig:"BOTH,overwrite,default=both"`
+		Renamed    string `env:"NEW_NAME,default=new"         envconfig:"OLD_NAME,default=old"`
+		Prefixes   string `env:",prefix=NEW_"                 envconfig:",prefix=OLD_"`
+		Mixed      string `env:",prefix=MIX_"                 envconfig:"MIXED"`
+		Nothing    string `json:"nothing"`
+	}
+
+	type want struct {
+		env       *enw.Env
+		prefix    string
+		conflicts []composite.Conflict
+	}
+
+	env := func(name string, field string, tag enw.Tag) *enw.Env {
+		return &enw.Env{Var: name, Field: field, Type: "string", Path: "some.path", Package: "some/pkg", Tag: tag}
+	}
+
+	tests := []struct {
+		name   string
+		field  string
+		policy composite.Policy
+		want   want
+	}{
+		{
+			name:   "only first dialect",
+			field:  "OnlyFirst",
+			policy: composite.First,
+			want:   want{env: env("FIRST", "OnlyFirst", enw.Tag{Required: true}), conflicts: []composite.Conflict{}},
+		},
+		{
+			name:   "only second dialect",
+			field:  "OnlySecond",
+			policy: composite.First,
+			want: want{
+				env:       env("SECOND", "OnlySecond", enw.Tag{Default: "second"}),
+				conflicts: []composite.Conflict{},
+			},
+		},
+		{
+			name:   "both dialects with first policy",
+			field:  "Both",
+			policy: composite.First,
+			want: want{
+				env:       env("BOTH", "Both", enw.Tag{Required: true}),
+				conflicts: []composite.Conflict{},
+			},
+		},
+		{
+			name:   "both dialects with merge policy",
+			field:  "Both",
+			policy: composite.Merge,
+			want: want{
+				env:       env("BOTH", "Both", enw.Tag{Default: "both", Required: true, Overwrite: true}),
+				conflicts: []composite.Conflict{},
+			},
+		},
+		{
+			name:   "conflicting names and defaults",
+			field:  "Renamed",
+			policy: composite.Merge,
+			want: want{
+				env: env("NEW_NAME", "Renamed", enw.Tag{Default: "new"}),
+				conflicts: []composite.Conflict{
+					{
+						Path:   "some.path",
+						Field:  "Renamed",
+						Kind:   composite.VarConflict,
+						Values: []string{"NEW_NAME", "OLD_NAME"},
+					},
+					{
+						Path:   "some.path",
+						Field:  "Renamed",
+						Kind:   composite.DefaultConflict,
+						Values: []string{"new", "old"},
+					},
+				},
+			},
+		},
+		{
+			name:   "conflicting prefixes",
+			field:  "Prefixes",
+			policy: composite.Merge,
+			want: want{
+				env:    nil,
+				prefix: "NEW_",
+				conflicts: []composite.Conflict{
+					{
+						Path:   "some.path",
+						Field:  "Prefixes",
+						Kind:   composite.PrefixConflict,
+						Values: []string{"NEW_", "OLD_"},
+					},
+				},
+			},
+		},
+		{
+			name:   "prefix and name from different dialects with first policy",
+			field:  "Mixed",
+			policy: composite.First,
+			want: want{
+				env:       env("MIXED", "Mixed", enw.Tag{Empty: true}),
+				prefix:    "MIX_",
+				conflicts: []composite.Conflict{},
+			},
+		},
+		{
+			name:   "no tags at all",
+			field:  "Nothing",
+			policy: composite.Merge,
+			want:   want{env: nil, conflicts: []composite.Conflict{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := composite.NewWithConfig(composite.Config{Parsers: parsers(), Policy: test.policy})
+
+			require.NoError(t, err)
+
+			field, ok := reflect.TypeFor[sampleStruct]().FieldByName(test.field)
+
+			require.True(t, ok)
+
+			got, prefix := obj.Parse(&field, "some.path", "some/pkg")
+
+			assert.Equal(t, test.want.env, got)
+			assert.Equal(t, test.want.prefix, prefix)
+			assert.Equal(t, test.want.conflicts, obj.Conflicts())
+		})
+	}
+}
+
+func TestParserConflicts(t *testing.T) {
+	t.Parallel()
+
+	type sampleStruct struct {
+		Renamed string `env:"NEW_NAME" envconfig:"OLD_NAME"`
+	}
+
+	obj, err := composite.New(parsers()...)
+
+	require.NoError(t, err)
+
+	field, ok := reflect.TypeFor[sampleStruct]().FieldByName("Renamed")
+
+	require.True(t, ok)
+
+	for _, path := range []string{"cfg->List->*->Renamed", "cfg->List->0->Renamed", "cfg->List->1->Renamed"} {
+		_, _ = obj.Parse(&field, path, "some/pkg")
+	}
+
+	want := []composite.Conflict{
+		{
+			Path:   "cfg->List->*->Renamed",
+			Field:  "Renamed",
+			Kind:   composite.VarConflict,
+			Values: []string{"NEW_NAME", "OLD_NAME"},
+		},
+	}
+
+	assert.Equal(t, want, obj.Conflicts())
+
+	obj.Reset()
+
+	assert.Empty(t, obj.Conflicts())
+
+	_, _ = obj.Parse(&field, "cfg->List->*->Renamed", "some/pkg")
+
+	assert.Equal(t, want, obj.Conflicts())
+}
+
+func TestParserConflictsSameDeclaration(t *testing.T) {
+	t.Parallel()
+
+	type primary struct {
+		Host string `env:"HOST" envconfig:"ADDR"`
+	}
+
+	type replica struct {
+		Host string `env:"HOST" envconfig:"ADDR"`
+	}
+
+	type sampleConfig struct {
+		Primary  primary
+		Replicas map[string]replica
+	}
+
+	parser, err := composite.New(parsers()...)
+
+	require.NoError(t, err)
+
+	collector, err := enw.NewCollector(parser)
+
+	require.NoError(t, err)
+
+	_, err = collector.Collect(sampleConfig{
+		Primary:  primary{Host: ""},
+		Replicas: map[string]replica{"eu": {Host: ""}, "us": {Host: ""}},
+	})
+
+	require.NoError(t, err)
+
+	want := []composite.Conflict{
+		{
+			Path:   "sampleConfig->Primary->Host",
+			Field:  "Host",
+			Kind:   composite.VarConflict,
+			Values: []string{"HOST", "ADDR"},
+		},
+		{
+			Path:   "sampleConfig->Replicas->eu->Host",
+			Field:  "Host",
+			Kind:   composite.VarConflict,
+			Values: []string{"HOST", "ADDR"},
+		},
+	}
+
+	assert.Equal(t, want, parser.Conflicts())
+}
+
+func TestParserCollect(t *testing.T) {
+	t.Parallel()
+
+	type Database struct {
+		Host string `env:"HOST" envconfig:"HOST"`
+	}
+
+	type sampleConfig struct {
+		DB   Database `env:",prefix=DB_"`
+		Port int      `envconfig:"PORT,default=8080"`
+	}
+
+	parser, err := composite.New(parsers()...)
+
+	require.NoError(t, err)
+
+	collector, err := enw.NewCollector(parser)
+
+	require.NoError(t, err)
+
+	got, err := collector.Collect(sampleConfig{})
+
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "DB_HOST", got[0].Var)
+	assert.Equal(t, "PORT", got[1].Var)
+	assert.Empty(t, parser.Conflicts())
+}
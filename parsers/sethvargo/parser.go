@@ -4,16 +4,28 @@ import (
 	"cmp"
 	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/ex"
 )
 
 const (
-	tagKeyPrefix   = "prefix="
-	tagKeyDefault  = "default="
-	tagKeyRequired = "required"
+	tagKeyPrefix      = "prefix="
+	tagKeyDefault     = "default="
+	tagKeyDelimiter   = "delimiter="
+	tagKeySeparator   = "separator="
+	tagKeyRequired    = "required"
+	tagKeyOverwrite   = "overwrite"
+	tagKeyNoInit      = "noinit"
+	tagKeyDecodeUnset = "decodeunset"
+
+	tagSplit = ","
 
 	defaultTagKey = "env"
+
+	ErrInvalidName   ex.Const = "invalid name"
+	ErrUnknownOption ex.Const = "unknown option"
 )
 
 type (
@@ -49,31 +61,9 @@ func (p *Parser) Parse(field *reflect.StructField, path string, pkg string) (*en
 		return nil, ""
 	}
 
-	var (
-		tag    enw.Tag
-		prefix string
-		parts  = strings.Split(tagVal, ",")
-	)
-
-	for _, part := range parts[1:] {
-		trimmedPart := strings.TrimSpace(part)
-
-		if val, found := strings.CutPrefix(trimmedPart, tagKeyPrefix); found {
-			prefix = val
-		}
-
-		if val, found := strings.CutPrefix(trimmedPart, tagKeyDefault); found {
-			tag.Default = val
-		}
-
-		if trimmedPart == tagKeyRequired {
-			tag.Required = true
-		}
-	}
-
-	tag.Empty = prefix == "" && tag.Default == "" && !tag.Required
-
-	value := strings.TrimSpace(parts[0])
+	// go-envconfig refuses broken tags at runtime, but here we still report
+	// everything that was recognized before the broken part
+	value, prefix, tag, _ := ParseTag(tagVal)
 	if value == "" {
 		return nil, prefix
 	}
@@ -94,6 +84,74 @@ func (p *Parser) Parse(field *reflect.StructField, path string, pkg string) (*en
 		Path:    path,
 		Package: pkg,
 		Source:  "",
+		Target:  "",
 		Tag:     tag,
 	}, prefix
 }
+
+// ParseTag splits the tag value the same way go-envconfig does: options are
+// case-insensitive and `default=` swallows the rest of the tag, including
+// commas. The comma cannot be escaped, as in go-envconfig.
+func ParseTag(tagVal string) (string, string, enw.Tag, error) {
+	var (
+		tag    enw.Tag
+		prefix string
+		err    error
+		parts  = strings.Split(tagVal, tagSplit)
+		value  = strings.TrimSpace(parts[0])
+	)
+
+	if value != "" && !validName(value) {
+		err = ErrInvalidName.Reason(value)
+	}
+
+loop:
+	for i, part := range parts[1:] {
+		trimmedPart := strings.TrimLeftFunc(part, unicode.IsSpace)
+		search := strings.ToLower(trimmedPart)
+
+		switch {
+		case search == tagKeyRequired:
+			tag.Required = true
+		case search == tagKeyOverwrite:
+			tag.Overwrite = true
+		case search == tagKeyNoInit:
+			tag.NoInit = true
+		case search == tagKeyDecodeUnset:
+			tag.DecodeUnset = true
+		case strings.HasPrefix(search, tagKeyPrefix):
+			prefix = strings.TrimPrefix(trimmedPart, tagKeyPrefix)
+		case strings.HasPrefix(search, tagKeyDelimiter):
+			tag.Delimiter = strings.TrimPrefix(trimmedPart, tagKeyDelimiter)
+		case strings.HasPrefix(search, tagKeySeparator):
+			tag.Separator = strings.TrimPrefix(trimmedPart, tagKeySeparator)
+		case strings.HasPrefix(search, tagKeyDefault):
+			rest := strings.TrimLeft(strings.Join(parts[i+1:], tagSplit), " ")
+			tag.Default = strings.TrimPrefix(rest, tagKeyDefault)
+
+			break loop
+		default:
+			err = cmp.Or(err, ErrUnknownOption.Reason(trimmedPart))
+
+			break loop
+		}
+	}
+
+	tag.Empty = prefix == "" && tag.Default == "" && tag.Delimiter == "" && tag.Separator == "" &&
+		!tag.Required && !tag.Overwrite && !tag.NoInit && !tag.DecodeUnset
+
+	return value, prefix, tag, err
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		letter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		number := r >= '0' && r <= '9'
+
+		if (i == 0 && !letter) || (!letter && !number && r != '_') {
+			return false
+		}
+	}
+
+	return name != ""
+}
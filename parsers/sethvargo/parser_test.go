@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/therenotomorrow/enw"
 	"github.com/therenotomorrow/enw/parsers/sethvargo"
 )
@@ -73,6 +74,11 @@ func TestParserParse(t *testing.T) {
 		EmptyDefault     string    `env:"MY_VAR,default="`
 		JustAComma       string    `env:""`
 		WithDefault      string    `env:"MY_VAR,default=fallback"`
+		DefaultSwallows  []string  `env:"MY_VAR,default=a,b,required"`
+		WithExpansion    string    `env:"MY_VAR,default=http://${HOST}:$PORT/$HOST"`
+		WithFlags        *string   `env:"MY_VAR,overwrite,NoInit,DECODEUNSET"`
+		UnknownOption    string    `env:"MY_VAR,requierd,required"`
+		InvalidName      string    `env:"MY-VAR,required"`
 	}
 
 	type want struct {
@@ -160,9 +166,88 @@ func TestParserParse(t *testing.T) {
 					Type:    "string",
 					Path:    "some.path",
 					Package: "some/pkg",
-					Tag:     enw.Tag{Default: "fallback", Required: true, Empty: false},
+					// default= swallows the options after it, as in go-envconfig
+					Tag: enw.Tag{Default: "fallback,required,prefix=APP_", Required: false, Empty: false},
 				},
-				prefix: "APP_",
+				prefix: "",
+			},
+		},
+		{
+			name:  "default swallows the rest",
+			field: "DefaultSwallows",
+			want: want{
+				env: &enw.Env{
+					Var:     "MY_VAR",
+					Field:   "DefaultSwallows",
+					Type:    "[]string",
+					Path:    "some.path",
+					Package: "some/pkg",
+					Tag:     enw.Tag{Default: "a,b,required", Required: false, Empty: false},
+				},
+				prefix: "",
+			},
+		},
+		{
+			name:  "with expansion",
+			field: "WithExpansion",
+			want: want{
+				env: &enw.Env{
+					Var:     "MY_VAR",
+					Field:   "WithExpansion",
+					Type:    "string",
+					Path:    "some.path",
+					Package: "some/pkg",
+					Tag: enw.Tag{
+						Default: "http://${HOST}:$PORT/$HOST",
+						Empty:   false,
+					},
+				},
+				prefix: "",
+			},
+		},
+		{
+			name:  "with flags",
+			field: "WithFlags",
+			want: want{
+				env: &enw.Env{
+					Var:     "MY_VAR",
+					Field:   "WithFlags",
+					Type:    "*string",
+					Path:    "some.path",
+					Package: "some/pkg",
+					Tag:     enw.Tag{Overwrite: true, NoInit: true, DecodeUnset: true, Empty: false},
+				},
+				prefix: "",
+			},
+		},
+		{
+			name:  "unknown option stops parsing",
+			field: "UnknownOption",
+			want: want{
+				env: &enw.Env{
+					Var:     "MY_VAR",
+					Field:   "UnknownOption",
+					Type:    "string",
+					Path:    "some.path",
+					Package: "some/pkg",
+					Tag:     enw.Tag{Empty: true},
+				},
+				prefix: "",
+			},
+		},
+		{
+			name:  "invalid name",
+			field: "InvalidName",
+			want: want{
+				env: &enw.Env{
+					Var:     "MY-VAR",
+					Field:   "InvalidName",
+					Type:    "string",
+					Path:    "some.path",
+					Package: "some/pkg",
+					Tag:     enw.Tag{Required: true, Empty: false},
+				},
+				prefix: "",
 			},
 		},
 		{
@@ -175,9 +260,9 @@ func TestParserParse(t *testing.T) {
 					Type:    "string",
 					Path:    "some.path",
 					Package: "some/pkg",
-					Tag:     enw.Tag{Default: "fallback", Required: true, Empty: false},
+					Tag:     enw.Tag{Default: "fallback ,required, prefix=APP_ ", Required: false, Empty: false},
 				},
-				prefix: "APP_",
+				prefix: "",
 			},
 		},
 		{
@@ -234,3 +319,55 @@ func TestParserParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTag(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		err    error
+		value  string
+		prefix string
+	}
+
+	tests := []struct {
+		name   string
+		tagVal string
+		want   want
+	}{
+		{name: "valid", tagVal: "MY_VAR,prefix=APP_", want: want{value: "MY_VAR", prefix: "APP_", err: nil}},
+		{name: "lower case", tagVal: "my_var", want: want{value: "my_var", prefix: "", err: nil}},
+		{name: "no name", tagVal: ",prefix=APP_", want: want{value: "", prefix: "APP_", err: nil}},
+		{
+			name:   "starts with digit",
+			tagVal: "1VAR",
+			want:   want{value: "1VAR", prefix: "", err: sethvargo.ErrInvalidName},
+		},
+		{
+			name:   "unknown option",
+			tagVal: "MY_VAR,requierd,prefix=APP_",
+			want:   want{value: "MY_VAR", prefix: "", err: sethvargo.ErrUnknownOption},
+		},
+		{
+			name:   "comma cannot be escaped",
+			tagVal: `MY_VAR,separator=\,,delimiter=;`,
+			want:   want{value: "MY_VAR", prefix: "", err: sethvargo.ErrUnknownOption},
+		},
+		{
+			name:   "mixed case option",
+			tagVal: "MY_VAR,Prefix=APP_",
+			want:   want{value: "MY_VAR", prefix: "Prefix=APP_", err: nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			value, prefix, _, err := sethvargo.ParseTag(test.tagVal)
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.value, value)
+			assert.Equal(t, test.want.prefix, prefix)
+		})
+	}
+}
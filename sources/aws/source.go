@@ -0,0 +1,226 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	separator = "_"
+
+	ErrMissingParameters ex.Const = "missing path or secrets"
+	ErrInvalidPath       ex.Const = "invalid path, must start with /"
+	ErrAWSError          ex.Const = "aws error"
+)
+
+type (
+	Config struct {
+		Region          string
+		Endpoint        string
+		AccessKeyID     string
+		SecretAccessKey string
+		Path            string
+		Secrets         []string
+	}
+
+	SSM interface {
+		GetParametersByPath(
+			ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options),
+		) (*ssm.GetParametersByPathOutput, error)
+	}
+
+	SecretsManager interface {
+		GetSecretValue(
+			ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options),
+		) (*secretsmanager.GetSecretValueOutput, error)
+	}
+
+	Source struct {
+		ssm     SSM
+		secrets SecretsManager
+		config  Config
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.Path == "" && len(c.Secrets) == 0 {
+		return ErrMissingParameters
+	}
+
+	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+		return ErrInvalidPath
+	}
+
+	return nil
+}
+
+func (c *Config) options() []func(*awsconfig.LoadOptions) error {
+	options := make([]func(*awsconfig.LoadOptions) error, 0)
+
+	if c.Region != "" {
+		options = append(options, awsconfig.WithRegion(c.Region))
+	}
+
+	if c.AccessKeyID != "" {
+		provider := credentials.NewStaticCredentialsProvider(c.AccessKeyID, c.SecretAccessKey, "")
+		options = append(options, awsconfig.WithCredentialsProvider(provider))
+	}
+
+	return options
+}
+
+func (c *Config) endpoint() *string {
+	if c.Endpoint == "" {
+		return nil
+	}
+
+	return aws.String(c.Endpoint)
+}
+
+func NewWithConfig(ctx context.Context, config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Path != "" && !strings.HasSuffix(config.Path, "/") {
+		config.Path += "/"
+	}
+
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, config.options()...)
+	if err != nil {
+		return nil, ErrAWSError.Because(err)
+	}
+
+	return &Source{
+		ssm: ssm.NewFromConfig(awsConfig, func(options *ssm.Options) {
+			options.BaseEndpoint = config.endpoint()
+		}),
+		secrets: secretsmanager.NewFromConfig(awsConfig, func(options *secretsmanager.Options) {
+			options.BaseEndpoint = config.endpoint()
+		}),
+		config: config,
+	}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+func (s *Source) WithMocks(mocks ...any) *Source {
+	clone := &Source{ssm: s.ssm, secrets: s.secrets, config: s.config}
+
+	for _, mock := range mocks {
+		if impl, ok := mock.(SSM); ok {
+			clone.ssm = impl
+		}
+
+		if impl, ok := mock.(SecretsManager); ok {
+			clone.secrets = impl
+		}
+	}
+
+	return clone
+}
+
+// Extract reads the parameters under the path and then the secrets, so the
+// secrets override the parameters with the same keys.
+func (s *Source) Extract(ctx context.Context) (map[string]string, error) {
+	envs := make(map[string]string)
+
+	if s.config.Path != "" {
+		err := s.parameters(ctx, envs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range s.config.Secrets {
+		err := s.secret(ctx, id, envs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return envs, nil
+}
+
+func (s *Source) parameters(ctx context.Context, envs map[string]string) error {
+	input := &ssm.GetParametersByPathInput{ //nolint:exhaustruct // too many options
+		Path:           aws.String(s.config.Path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	}
+
+	pages := ssm.NewGetParametersByPathPaginator(s.ssm, input)
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return ErrAWSError.Because(err)
+		}
+
+		for _, parameter := range page.Parameters {
+			name := strings.TrimPrefix(aws.ToString(parameter.Name), s.config.Path)
+			envs[Key(name)] = aws.ToString(parameter.Value)
+		}
+	}
+
+	return nil
+}
+
+func (s *Source) secret(ctx context.Context, id string, envs map[string]string) error {
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)} //nolint:exhaustruct // latest version
+
+	output, err := s.secrets.GetSecretValue(ctx, input)
+	if err != nil {
+		return ErrAWSError.Because(err)
+	}
+
+	text := aws.ToString(output.SecretString)
+
+	// the binary secrets are read as the text ones, unless they are not text
+	if output.SecretString == nil && output.SecretBinary != nil {
+		text = string(output.SecretBinary)
+
+		if !utf8.Valid(output.SecretBinary) {
+			text = base64.StdEncoding.EncodeToString(output.SecretBinary)
+		}
+	}
+
+	data := make(map[string]any)
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	// plain text secrets are kept as one variable named after the secret
+	if decoder.Decode(&data) != nil {
+		name := cmp.Or(aws.ToString(output.Name), id)
+		name = name[strings.LastIndex(name, "/")+1:]
+		envs[Key(name)] = text
+
+		return nil
+	}
+
+	for key, val := range flatten.Map(data, separator) {
+		envs[Key(key)] = val
+	}
+
+	return nil
+}
+
+// Key turns the parameter name like `db/host-name` into `DB_HOST_NAME`.
+func Key(name string) string {
+	return flatten.Key(name)
+}
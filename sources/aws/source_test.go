@@ -0,0 +1,206 @@
+package aws_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/aws"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config aws.Config
+		name   string
+	}{
+		{name: "path", config: aws.Config{Path: "/billing/prod"}, err: nil},
+		{name: "secrets", config: aws.Config{Secrets: []string{"billing"}}, err: nil},
+		{name: "missing parameters", config: aws.Config{}, err: aws.ErrMissingParameters},
+		{name: "invalid path", config: aws.Config{Path: "billing/prod"}, err: aws.ErrInvalidPath},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "DB_HOST", aws.Key("db/host"))
+	assert.Equal(t, "DB_HOST_NAME", aws.Key("/db/host-name/"))
+	assert.Equal(t, "API_V1_URL", aws.Key("api.v1/url"))
+}
+
+// server speaks the subset of the SSM and Secrets Manager JSON protocol the source uses.
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	pages := map[string]string{
+		"": `{"Parameters": [{"Name": "/billing/prod/db/host", "Value": "db"}], "NextToken": "2"}`,
+		"2": `{"Parameters": [
+			{"Name": "/billing/prod/db/password", "Value": "decrypted", "Type": "SecureString"},
+			{"Name": "/billing/prod/log-level", "Value": "info"}
+		]}`,
+	}
+
+	secrets := map[string]string{
+		"billing/prod": `{"Name": "billing/prod",
+			"SecretString": "{\"db-password\": \"secret\", \"stripe\": {\"api.key\": 42}}"}`,
+		"billing/token": `{"Name": "billing/token", "SecretString": "plain-text"}`,
+		// {"queue-url": "sqs"} and the bytes that are not text
+		"billing/queue": `{"Name": "billing/queue", "SecretBinary": "eyJxdWV1ZS11cmwiOiAic3FzIn0="}`,
+		"billing/cert":  `{"Name": "billing/cert", "SecretBinary": "/wD+"}`,
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]any
+
+		_ = json.NewDecoder(r.Body).Decode(&input)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParametersByPath":
+			if input["Path"] != "/billing/prod/" || input["Recursive"] != true || input["WithDecryption"] != true {
+				fail(w, "ValidationException")
+
+				return
+			}
+
+			token, _ := input["NextToken"].(string)
+			_, _ = w.Write([]byte(pages[token]))
+		case "secretsmanager.GetSecretValue":
+			id, _ := input["SecretId"].(string)
+
+			body, ok := secrets[id]
+			if !ok {
+				fail(w, "ResourceNotFoundException")
+
+				return
+			}
+
+			_, _ = w.Write([]byte(body))
+		default:
+			fail(w, "UnknownOperationException")
+		}
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func fail(w http.ResponseWriter, kind string) {
+	w.WriteHeader(http.StatusBadRequest)
+
+	_, _ = w.Write([]byte(`{"__type": "` + kind + `", "message": "failed"}`))
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	srv := server(t)
+
+	tests := []struct {
+		err    error
+		want   map[string]string
+		config aws.Config
+		name   string
+	}{
+		{
+			name:   "parameters",
+			config: aws.Config{Path: "/billing/prod"},
+			want:   map[string]string{"DB_HOST": "db", "DB_PASSWORD": "decrypted", "LOG_LEVEL": "info"},
+			err:    nil,
+		},
+		{
+			name:   "parameters and secrets",
+			config: aws.Config{Path: "/billing/prod/", Secrets: []string{"billing/prod", "billing/token"}},
+			want: map[string]string{
+				"DB_HOST": "db", "DB_PASSWORD": "secret", "LOG_LEVEL": "info",
+				"STRIPE_API_KEY": "42", "TOKEN": "plain-text",
+			},
+			err: nil,
+		},
+		{
+			name:   "binary secrets",
+			config: aws.Config{Secrets: []string{"billing/queue", "billing/cert"}},
+			want:   map[string]string{"QUEUE_URL": "sqs", "CERT": "/wD+"},
+			err:    nil,
+		},
+		{
+			name:   "invalid path",
+			config: aws.Config{Path: "/billing/"},
+			want:   nil,
+			err:    aws.ErrAWSError,
+		},
+		{
+			name:   "secret not found",
+			config: aws.Config{Secrets: []string{"missing"}},
+			want:   nil,
+			err:    aws.ErrAWSError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.Region = "eu-west-1"
+			test.config.Endpoint = srv.URL
+			test.config.AccessKeyID = "key"
+			test.config.SecretAccessKey = "secret"
+
+			obj, err := aws.NewWithConfig(t.Context(), test.config)
+
+			require.NoError(t, err)
+
+			got, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+type secretsMock struct{}
+
+func (secretsMock) GetSecretValue(
+	context.Context, *secretsmanager.GetSecretValueInput, ...func(*secretsmanager.Options),
+) (*secretsmanager.GetSecretValueOutput, error) {
+	text := "mocked"
+
+	return &secretsmanager.GetSecretValueOutput{SecretString: &text}, nil
+}
+
+func TestSourceWithMocks(t *testing.T) {
+	t.Parallel()
+
+	obj, err := aws.NewWithConfig(t.Context(), aws.Config{Region: "eu-west-1", Secrets: []string{"arn/mock"}})
+
+	require.NoError(t, err)
+
+	_, err = aws.NewWithConfig(t.Context(), aws.Config{})
+
+	require.ErrorIs(t, err, aws.ErrMissingParameters)
+
+	got, err := obj.WithMocks(nil, secretsMock{}).Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"MOCK": "mocked"}, got)
+	assert.Equal(t, []string{"arn/mock"}, obj.Config().Secrets)
+}
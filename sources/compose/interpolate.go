@@ -0,0 +1,136 @@
+package compose
+
+import (
+	"strings"
+)
+
+// Interpolate substitutes the variables the same way compose does:
+// `$VAR`, `${VAR}`, `${VAR:-default}`, `${VAR-default}`, `${VAR:?error}`,
+// `${VAR?error}`, `${VAR:+alternative}` and `${VAR+alternative}`, the
+// defaults may hold the variables too and `$$` is the escaped dollar.
+func Interpolate(text string, lookup func(name string) (string, bool)) (string, error) {
+	var out strings.Builder
+
+	for {
+		pos := strings.IndexByte(text, '$')
+		if pos < 0 {
+			out.WriteString(text)
+
+			return out.String(), nil
+		}
+
+		out.WriteString(text[:pos])
+		text = text[pos+1:]
+
+		switch {
+		case strings.HasPrefix(text, "$"):
+			out.WriteByte('$')
+
+			text = text[1:]
+		case strings.HasPrefix(text, "{"):
+			end := closing(text)
+			if end < 0 {
+				return "", ErrInvalidInterpolation.Reason("unclosed brace in $" + text)
+			}
+
+			value, err := expand(text[1:end], lookup)
+			if err != nil {
+				return "", err
+			}
+
+			out.WriteString(value)
+
+			text = text[end+1:]
+		default:
+			size := nameSize(text)
+			if size == 0 {
+				return "", ErrInvalidInterpolation.Reason("$" + text)
+			}
+
+			value, _ := lookup(text[:size])
+			out.WriteString(value)
+
+			text = text[size:]
+		}
+	}
+}
+
+// closing finds the brace that closes the first one, the nested
+// substitutions in the defaults have their own braces.
+func closing(text string) int {
+	depth := 0
+
+	for i := range len(text) {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func expand(expr string, lookup func(name string) (string, bool)) (string, error) {
+	size := nameSize(expr)
+	if size == 0 {
+		return "", ErrInvalidInterpolation.Reason("${" + expr + "}")
+	}
+
+	name, rest := expr[:size], expr[size:]
+	value, set := lookup(name)
+
+	if rest == "" {
+		return value, nil
+	}
+
+	operator := rest[:1]
+	if strings.HasPrefix(rest, ":") && len(rest) > 1 {
+		operator = rest[:2]
+	}
+
+	word := rest[len(operator):]
+	empty := !set || (strings.HasPrefix(operator, ":") && value == "")
+
+	switch operator {
+	case ":-", "-":
+		if empty {
+			return Interpolate(word, lookup)
+		}
+
+		return value, nil
+	case ":?", "?":
+		if empty {
+			return "", ErrMissingVariable.Reason(name + ": " + word)
+		}
+
+		return value, nil
+	case ":+", "+":
+		if empty {
+			return "", nil
+		}
+
+		return Interpolate(word, lookup)
+	default:
+		return "", ErrInvalidInterpolation.Reason("${" + expr + "}")
+	}
+}
+
+func nameSize(text string) int {
+	for i := range len(text) {
+		char := text[i]
+		letter := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+		number := char >= '0' && char <= '9'
+
+		if !letter && (!number || i == 0) {
+			return i
+		}
+	}
+
+	return len(text)
+}
@@ -0,0 +1,333 @@
+// Package compose reproduces the environment that docker compose gives to
+// a service: the `env_file` entries in order, then `environment` on top.
+// The compose file is interpolated with the shell environment and the
+// project `.env` file, the shell wins as in compose.
+package compose
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/therenotomorrow/ex"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultEnvFile = ".env"
+
+	ErrMissingFilename      ex.Const = "missing filename"
+	ErrMissingService       ex.Const = "missing service"
+	ErrMissingFile          ex.Const = "missing file"
+	ErrInvalidFile          ex.Const = "invalid compose file"
+	ErrServiceNotFound      ex.Const = "service not found"
+	ErrInvalidInterpolation ex.Const = "invalid interpolation format"
+	ErrMissingVariable      ex.Const = "required variable is missing a value"
+)
+
+type (
+	// Config selects the service of the compose file. EnvFile is the project
+	// `.env` used for the interpolation, by default the one next to the
+	// compose file if it exists. Environ replaces the shell environment.
+	Config struct {
+		Environ  map[string]string
+		Filename string
+		Service  string
+		EnvFile  string
+	}
+
+	Source struct {
+		config Config
+	}
+
+	project struct {
+		Services map[string]yaml.Node `yaml:"services"`
+	}
+
+	service struct {
+		Environment yaml.Node `yaml:"environment"`
+		EnvFile     yaml.Node `yaml:"env_file"`
+	}
+
+	envFile struct {
+		Required *bool  `yaml:"required"`
+		Path     string `yaml:"path"`
+	}
+)
+
+func (c *Config) Validate() error {
+	switch {
+	case c.Filename == "":
+		return ErrMissingFilename
+	case c.Service == "":
+		return ErrMissingService
+	}
+
+	return nil
+}
+
+func New(filename string, service string) (*Source, error) {
+	return NewWithConfig(Config{Environ: nil, Filename: filename, Service: service, EnvFile: ""})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{config: config}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+func (s *Source) Extract(_ context.Context) (map[string]string, error) {
+	lookup, err := s.lookup()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := read(s.config.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var proj project
+
+	err = yaml.Unmarshal(data, &proj)
+	if err != nil {
+		return nil, ErrInvalidFile.Because(err)
+	}
+
+	node, ok := proj.Services[s.config.Service]
+	if !ok {
+		return nil, ErrServiceNotFound.Reason(s.config.Service)
+	}
+
+	// only the selected service is interpolated, so the required variables
+	// of the other services do not break it
+	err = interpolateNode(&node, lookup, make(map[*yaml.Node]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	var svc service
+
+	err = node.Decode(&svc)
+	if err != nil {
+		return nil, ErrInvalidFile.Because(err)
+	}
+
+	envs, err := s.envFiles(&svc.EnvFile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = environment(envs, &svc.Environment, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	return envs, nil
+}
+
+func read(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrMissingFile.Reason(filename)
+	}
+
+	if err != nil {
+		return nil, ex.Unexpected(err)
+	}
+
+	return data, nil
+}
+
+func (s *Source) lookup() (func(name string) (string, bool), error) {
+	shell := s.config.Environ
+	if shell == nil {
+		shell = make(map[string]string)
+
+		for _, entry := range os.Environ() {
+			key, val, _ := strings.Cut(entry, "=")
+			shell[key] = val
+		}
+	}
+
+	vars := make(map[string]string)
+
+	filename := s.config.EnvFile
+	if filename == "" {
+		filename = filepath.Join(filepath.Dir(s.config.Filename), defaultEnvFile)
+	}
+
+	data, err := read(filename)
+
+	switch {
+	case errors.Is(err, ErrMissingFile) && s.config.EnvFile == "":
+	case err != nil:
+		return nil, err
+	default:
+		vars, err = godotenv.Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidFile.Because(err)
+		}
+	}
+
+	maps.Copy(vars, shell)
+
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+
+		return val, ok
+	}, nil
+}
+
+// interpolateNode substitutes the variables in the values, the keys are
+// kept as they are written. The anchors are interpolated once, even when
+// they are used several times.
+func interpolateNode(node *yaml.Node, lookup func(name string) (string, bool), visited map[*yaml.Node]bool) error {
+	if visited[node] {
+		return nil
+	}
+
+	visited[node] = true
+
+	switch node.Kind { //nolint:exhaustive // documents are not the services
+	case yaml.ScalarNode:
+		value, err := Interpolate(node.Value, lookup)
+		if err != nil {
+			return err
+		}
+
+		node.Value = value
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			err := interpolateNode(node.Content[i], lookup, visited)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			err := interpolateNode(child, lookup, visited)
+			if err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return interpolateNode(node.Alias, lookup, visited)
+	}
+
+	return nil
+}
+
+// envFiles reads the files in order, so the later ones override the earlier.
+// The entries are either paths or objects with the path and required flag.
+func (s *Source) envFiles(node *yaml.Node) (map[string]string, error) {
+	files := make([]envFile, 0)
+
+	switch node.Kind { //nolint:exhaustive // other kinds are invalid
+	case 0:
+	case yaml.ScalarNode:
+		files = append(files, envFile{Required: nil, Path: node.Value})
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			file := envFile{Required: nil, Path: item.Value}
+
+			if item.Kind == yaml.MappingNode {
+				err := item.Decode(&file)
+				if err != nil {
+					return nil, ErrInvalidFile.Because(err)
+				}
+			}
+
+			files = append(files, file)
+		}
+	default:
+		return nil, ErrInvalidFile.Reason("env_file must be a string or a list")
+	}
+
+	envs := make(map[string]string)
+
+	for _, file := range files {
+		path := file.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(s.config.Filename), path)
+		}
+
+		data, err := read(path)
+		if errors.Is(err, ErrMissingFile) && file.Required != nil && !*file.Required {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		vars, err := godotenv.Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidFile.Because(err)
+		}
+
+		maps.Copy(envs, vars)
+	}
+
+	return envs, nil
+}
+
+// environment applies the map or list syntax, the variables without values
+// are taken from the shell and skipped when the shell has no such variable.
+func environment(envs map[string]string, node *yaml.Node, lookup func(name string) (string, bool)) error {
+	set := func(key string, value string, ok bool) {
+		if !ok {
+			value, ok = lookup(key)
+		}
+
+		if ok {
+			envs[key] = value
+		} else {
+			delete(envs, key)
+		}
+	}
+
+	switch node.Kind { //nolint:exhaustive // other kinds are invalid
+	case 0:
+	case yaml.MappingNode:
+		// the merged anchors go first, the keys of the map override them
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].ShortTag() == "!!merge" {
+				err := environment(envs, node.Content[i+1], lookup)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.ShortTag() != "!!merge" {
+				set(key.Value, value.Value, value.ShortTag() != "!!null")
+			}
+		}
+	case yaml.AliasNode:
+		return environment(envs, node.Alias, lookup)
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			key, value, ok := strings.Cut(item.Value, "=")
+			set(key, value, ok)
+		}
+	default:
+		return ErrInvalidFile.Reason("environment must be a map or a list")
+	}
+
+	return nil
+}
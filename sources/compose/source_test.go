@@ -0,0 +1,237 @@
+package compose_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/compose"
+	"github.com/therenotomorrow/ex"
+)
+
+const filename = "testdata/compose.yaml"
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config compose.Config
+		name   string
+	}{
+		{name: "valid", config: compose.Config{Filename: filename, Service: "api"}, err: nil},
+		{name: "missing filename", config: compose.Config{Service: "api"}, err: compose.ErrMissingFilename},
+		{name: "missing service", config: compose.Config{Filename: filename}, err: compose.ErrMissingService},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := compose.New(filename, "api")
+
+	require.NoError(t, err)
+	assert.Equal(t, compose.Config{Environ: nil, Filename: filename, Service: "api", EnvFile: ""}, obj.Config())
+
+	obj, err = compose.New(filename, "")
+
+	require.ErrorIs(t, err, compose.ErrMissingService)
+	assert.Nil(t, obj)
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Parallel()
+
+	vars := map[string]string{"SET": "value", "EMPTY": "", "NESTED": "nested"}
+	lookup := func(name string) (string, bool) {
+		val, ok := vars[name]
+
+		return val, ok
+	}
+
+	tests := []struct {
+		err  error
+		name string
+		text string
+		want string
+	}{
+		{name: "plain", text: "no variables", want: "no variables", err: nil},
+		{name: "simple", text: "$SET and ${SET}", want: "value and value", err: nil},
+		{name: "name ends", text: "$SET.$SET-$SET/x", want: "value.value-value/x", err: nil},
+		{name: "unset", text: "[$UNSET${UNSET}]", want: "[]", err: nil},
+		{name: "escaped", text: "$$SET costs $$5", want: "$SET costs $5", err: nil},
+		{name: "default unset", text: "${UNSET:-def} ${UNSET-def}", want: "def def", err: nil},
+		{name: "default empty", text: "[${EMPTY:-def}] [${EMPTY-def}]", want: "[def] []", err: nil},
+		{name: "default set", text: "${SET:-def}", want: "value", err: nil},
+		{name: "nested default", text: "${UNSET:-${NESTED}-${UNSET:-x}}", want: "nested-x", err: nil},
+		{name: "alternative", text: "[${SET:+alt}] [${EMPTY:+alt}]", want: "[alt] []", err: nil},
+		{name: "alternative unset", text: "[${EMPTY+alt}] [${UNSET+alt}]", want: "[alt] []", err: nil},
+		{name: "required set", text: "${SET:?must} ${EMPTY?must}", want: "value ", err: nil},
+		{name: "required empty", text: "${EMPTY:?must be set}", want: "", err: compose.ErrMissingVariable},
+		{name: "required unset", text: "${UNSET?must be set}", want: "", err: compose.ErrMissingVariable},
+		{name: "unclosed", text: "${SET", want: "", err: compose.ErrInvalidInterpolation},
+		{name: "invalid name", text: "${1SET}", want: "", err: compose.ErrInvalidInterpolation},
+		{name: "invalid operator", text: "${SET/x/y}", want: "", err: compose.ErrInvalidInterpolation},
+		{name: "lonely dollar", text: "costs $ 5", want: "", err: compose.ErrInvalidInterpolation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := compose.Interpolate(test.text, lookup)
+
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	shell := map[string]string{"DEBUG": "1", "FROM_SHELL": "shell", "LOG_LEVEL": "warn", "WORKERS": ""}
+
+	type want struct {
+		envs map[string]string
+		err  error
+	}
+
+	tests := []struct {
+		want   want
+		name   string
+		config compose.Config
+	}{
+		{
+			name:   "map syntax",
+			config: compose.Config{Environ: shell, Filename: filename, Service: "api"},
+			want: want{
+				envs: map[string]string{
+					"COMMON":     "api",
+					"API_KEY":    "k3y",
+					"LOG_LEVEL":  "warn",
+					"DB_HOST":    "db",
+					"DB_PORT":    "5432",
+					"DB_URL":     "postgres://shop@db:5432/shop_dev",
+					"DEBUG":      "true",
+					"REGION":     "eu-west-1",
+					"EMPTY":      "",
+					"FROM_SHELL": "shell",
+					"PRICE":      "$5",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:   "list syntax",
+			config: compose.Config{Environ: shell, Filename: filename, Service: "worker"},
+			want: want{
+				envs: map[string]string{
+					"COMMON":     "overridden",
+					"DB_HOST":    "overridden",
+					"QUEUE":      "orders",
+					"WORKERS":    "4",
+					"FROM_SHELL": "shell",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:   "project env file only",
+			config: compose.Config{Environ: map[string]string{}, Filename: filename, Service: "worker"},
+			want: want{
+				envs: map[string]string{
+					"COMMON": "overridden", "DB_HOST": "overridden", "QUEUE": "orders", "WORKERS": "4",
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "custom project env file",
+			config: compose.Config{
+				Environ: map[string]string{}, Filename: filename, Service: "broken",
+				EnvFile: testFile(t, "SECRET=from-env-file\n"),
+			},
+			want: want{envs: map[string]string{"SECRET": "from-env-file"}, err: nil},
+		},
+		{
+			name:   "required variable",
+			config: compose.Config{Environ: shell, Filename: filename, Service: "broken"},
+			want:   want{envs: nil, err: compose.ErrMissingVariable},
+		},
+		{
+			name:   "missing env file",
+			config: compose.Config{Environ: shell, Filename: filename, Service: "missing"},
+			want:   want{envs: nil, err: compose.ErrMissingFile},
+		},
+		{
+			name: "missing project env file",
+			config: compose.Config{
+				Environ: shell, Filename: filename, Service: "api", EnvFile: "testdata/not-exist.env",
+			},
+			want: want{envs: nil, err: compose.ErrMissingFile},
+		},
+		{
+			name:   "service not found",
+			config: compose.Config{Environ: shell, Filename: filename, Service: "db"},
+			want:   want{envs: nil, err: compose.ErrServiceNotFound},
+		},
+		{
+			name:   "compose file not found",
+			config: compose.Config{Environ: shell, Filename: "testdata/not-exist.yaml", Service: "api"},
+			want:   want{envs: nil, err: compose.ErrMissingFile},
+		},
+		{
+			name: "invalid compose file",
+			config: compose.Config{
+				Environ: shell, Filename: testFile(t, "services: [api]\n"), Service: "api",
+			},
+			want: want{envs: nil, err: compose.ErrInvalidFile},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(compose.NewWithConfig(test.config))
+
+			envs, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.envs, envs)
+		})
+	}
+}
+
+func testFile(t *testing.T, content string) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "file")
+
+	ex.MustDo(os.WriteFile(name, []byte(content), 0o600))
+
+	return name
+}
+
+func TestSourceExtractShell(t *testing.T) {
+	t.Setenv("FROM_SHELL", "os")
+	t.Setenv("TAG", "ignored")
+
+	obj := ex.Must(compose.New(filename, "worker"))
+
+	envs, err := obj.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, "os", envs["FROM_SHELL"])
+}
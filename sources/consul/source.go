@@ -0,0 +1,193 @@
+package consul
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultAddress  = "http://127.0.0.1:8500"
+	defaultTimeout  = 10 * time.Second
+	defaultWaitTime = 5 * time.Minute
+
+	headerToken = "X-Consul-Token"
+	headerIndex = "X-Consul-Index"
+
+	ErrMissingPrefix ex.Const = "missing prefix"
+	ErrConsulError   ex.Const = "consul error"
+)
+
+type (
+	Config struct {
+		Address    string
+		Prefix     string
+		Token      string
+		Datacenter string
+		Timeout    time.Duration
+		WaitTime   time.Duration
+	}
+
+	Source struct {
+		client *http.Client
+		config Config
+		index  uint64
+		mutex  sync.Mutex
+	}
+
+	pair struct {
+		Value []byte `json:"Value"` //nolint:tagliatelle // consul api
+		Key   string `json:"Key"`   //nolint:tagliatelle // consul api
+	}
+)
+
+func (c *Config) Validate() error {
+	if strings.Trim(c.Prefix, "/") == "" {
+		return ErrMissingPrefix
+	}
+
+	return nil
+}
+
+func New(prefix string) (*Source, error) {
+	return NewWithConfig(Config{
+		Address:    defaultAddress,
+		Prefix:     prefix,
+		Token:      "",
+		Datacenter: "",
+		Timeout:    defaultTimeout,
+		WaitTime:   defaultWaitTime,
+	})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Address = strings.TrimSuffix(cmp.Or(config.Address, defaultAddress), "/")
+	config.Prefix = strings.Trim(config.Prefix, "/") + "/"
+	config.Timeout = cmp.Or(config.Timeout, defaultTimeout)
+	config.WaitTime = cmp.Or(config.WaitTime, defaultWaitTime)
+
+	return &Source{
+		client: &http.Client{}, //nolint:exhaustruct // the context limits the requests
+		config: config,
+		index:  0,
+		mutex:  sync.Mutex{},
+	}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+// Index is the Consul index of the last extracted data.
+func (s *Source) Index() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.index
+}
+
+func (s *Source) Extract(ctx context.Context) (map[string]string, error) {
+	return s.read(ctx, 0)
+}
+
+// Wait blocks until the keys under the prefix change after the last
+// extraction or the wait time passes, and returns the actual data.
+func (s *Source) Wait(ctx context.Context) (map[string]string, error) {
+	return s.read(ctx, s.Index())
+}
+
+func (s *Source) read(ctx context.Context, index uint64) (map[string]string, error) {
+	query := url.Values{"recurse": []string{"true"}}
+
+	if s.config.Datacenter != "" {
+		query.Set("dc", s.config.Datacenter)
+	}
+
+	// the client timeout must not interrupt the blocking query
+	timeout := s.config.Timeout
+
+	if index != 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", s.config.WaitTime.String())
+
+		timeout += s.config.WaitTime
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	endpoint := s.config.Address + "/v1/kv/" + s.config.Prefix + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, ErrConsulError.Because(err)
+	}
+
+	if s.config.Token != "" {
+		req.Header.Set(headerToken, s.config.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, ErrConsulError.Because(err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	return s.decode(resp)
+}
+
+func (s *Source) decode(resp *http.Response) (map[string]string, error) {
+	pairs := make([]pair, 0)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// consul answers 404 when nothing is stored under the prefix
+	case resp.StatusCode >= http.StatusBadRequest:
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint:mnd // enough for consul errors
+
+		return nil, ErrConsulError.Reason(resp.Status + ": " + strings.TrimSpace(string(text)))
+	default:
+		err := json.NewDecoder(resp.Body).Decode(&pairs)
+		if err != nil {
+			return nil, ErrConsulError.Because(err)
+		}
+	}
+
+	index, err := strconv.ParseUint(resp.Header.Get(headerIndex), 10, 64)
+	if err == nil {
+		s.mutex.Lock()
+		s.index = index
+		s.mutex.Unlock()
+	}
+
+	envs := make(map[string]string)
+
+	for _, pair := range pairs {
+		name := strings.TrimPrefix(pair.Key, s.config.Prefix)
+
+		// folders are stored as the keys with the trailing slash
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+
+		envs[flatten.Key(name)] = string(pair.Value)
+	}
+
+	return envs, nil
+}
@@ -0,0 +1,211 @@
+package consul_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/consul"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config consul.Config
+		name   string
+	}{
+		{name: "valid", config: consul.Config{Prefix: "config/billing"}, err: nil},
+		{name: "missing prefix", config: consul.Config{}, err: consul.ErrMissingPrefix},
+		{name: "root prefix", config: consul.Config{Prefix: "/"}, err: consul.ErrMissingPrefix},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := consul.New("/config/billing")
+
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:8500", obj.Config().Address)
+	assert.Equal(t, "config/billing/", obj.Config().Prefix)
+	assert.Equal(t, 5*time.Minute, obj.Config().WaitTime)
+
+	obj, err = consul.New("")
+
+	require.ErrorIs(t, err, consul.ErrMissingPrefix)
+	assert.Nil(t, obj)
+}
+
+// server speaks the subset of the Consul KV HTTP API the source uses, the
+// data changes on every blocking query.
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var index atomic.Int64
+
+	index.Store(10)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		switch {
+		case r.Header.Get("X-Consul-Token") == "denied":
+			http.Error(w, "ACL not found", http.StatusForbidden)
+
+			return
+		case query.Get("recurse") != "true":
+			http.Error(w, "not recursive", http.StatusBadRequest)
+
+			return
+		case query.Get("dc") == "broken":
+			w.Header().Set("X-Consul-Index", "1")
+			_, _ = w.Write([]byte("{"))
+
+			return
+		case r.URL.Path != "/v1/kv/config/billing/":
+			w.Header().Set("X-Consul-Index", "1")
+			http.NotFound(w, r)
+
+			return
+		}
+
+		host := "ZGI=" // db
+		if query.Get("dc") == "dc2" {
+			host = "ZGIy" // db2
+		}
+
+		if query.Get("index") != "" {
+			if query.Get("wait") != "1s" {
+				http.Error(w, "invalid wait", http.StatusBadRequest)
+
+				return
+			}
+
+			host = "bmV3" // new
+			index.Add(1)
+		}
+
+		w.Header().Set("X-Consul-Index", strconv.FormatInt(index.Load(), 10))
+		_, _ = w.Write([]byte(`[
+			{"Key": "config/billing/", "Value": null},
+			{"Key": "config/billing/db/", "Value": null},
+			{"Key": "config/billing/db/host", "Value": "` + host + `"},
+			{"Key": "config/billing/log-level", "Value": "aW5mbw=="}
+		]`))
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	srv := server(t)
+
+	tests := []struct {
+		err    error
+		want   map[string]string
+		config consul.Config
+		name   string
+	}{
+		{
+			name:   "success",
+			config: consul.Config{Prefix: "config/billing", Token: "token"},
+			want:   map[string]string{"DB_HOST": "db", "LOG_LEVEL": "info"},
+			err:    nil,
+		},
+		{
+			name:   "datacenter",
+			config: consul.Config{Prefix: "/config/billing/", Datacenter: "dc2"},
+			want:   map[string]string{"DB_HOST": "db2", "LOG_LEVEL": "info"},
+			err:    nil,
+		},
+		{
+			name:   "empty prefix",
+			config: consul.Config{Prefix: "config/missing"},
+			want:   map[string]string{},
+			err:    nil,
+		},
+		{
+			name:   "denied",
+			config: consul.Config{Prefix: "config/billing", Token: "denied"},
+			want:   nil,
+			err:    consul.ErrConsulError,
+		},
+		{
+			name:   "broken response",
+			config: consul.Config{Prefix: "config/billing", Datacenter: "broken"},
+			want:   nil,
+			err:    consul.ErrConsulError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.Address = srv.URL + "/"
+
+			obj, err := consul.NewWithConfig(test.config)
+
+			require.NoError(t, err)
+
+			got, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSourceWait(t *testing.T) {
+	t.Parallel()
+
+	srv := server(t)
+	obj, err := consul.NewWithConfig(consul.Config{Address: srv.URL, Prefix: "config/billing", WaitTime: time.Second})
+
+	require.NoError(t, err)
+	assert.Zero(t, obj.Index())
+
+	_, err = obj.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), obj.Index())
+
+	got, err := obj.Wait(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "new", "LOG_LEVEL": "info"}, got)
+	assert.Equal(t, uint64(11), obj.Index())
+}
+
+func TestSourceExtractUnavailable(t *testing.T) {
+	t.Parallel()
+
+	obj, err := consul.NewWithConfig(consul.Config{Address: "http://127.0.0.1:1", Prefix: "config"})
+
+	require.NoError(t, err)
+
+	got, err := obj.Extract(t.Context())
+
+	require.ErrorIs(t, err, consul.ErrConsulError)
+	assert.Nil(t, got)
+}
@@ -0,0 +1,153 @@
+package etcd
+
+import (
+	"cmp"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	defaultDialTimeout = 5 * time.Second
+	pageSize           = 500
+
+	ErrMissingEndpoints ex.Const = "missing endpoints"
+	ErrMissingPrefix    ex.Const = "missing prefix"
+	ErrInvalidRevision  ex.Const = "invalid revision, must not be negative"
+	ErrEtcdError        ex.Const = "etcd error"
+)
+
+type (
+	Config struct {
+		Prefix      string
+		Username    string
+		Password    string
+		Endpoints   []string
+		Revision    int64
+		DialTimeout time.Duration
+	}
+
+	Source struct {
+		client   *clientv3.Client
+		kv       clientv3.KV
+		config   Config
+		revision int64
+		mutex    sync.Mutex
+	}
+)
+
+func (c *Config) Validate() error {
+	switch {
+	case len(c.Endpoints) == 0:
+		return ErrMissingEndpoints
+	case strings.Trim(c.Prefix, "/") == "":
+		return ErrMissingPrefix
+	case c.Revision < 0:
+		return ErrInvalidRevision
+	}
+
+	return nil
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys are arbitrary, so only the separator after the prefix is added
+	if !strings.HasSuffix(config.Prefix, "/") {
+		config.Prefix += "/"
+	}
+
+	config.DialTimeout = cmp.Or(config.DialTimeout, defaultDialTimeout)
+
+	client, err := clientv3.New(clientv3.Config{ //nolint:exhaustruct // too many options
+		Endpoints:   config.Endpoints,
+		Username:    config.Username,
+		Password:    config.Password,
+		DialTimeout: config.DialTimeout,
+	})
+	if err != nil {
+		return nil, ErrEtcdError.Because(err)
+	}
+
+	return &Source{client: client, kv: client.KV, config: config, revision: 0, mutex: sync.Mutex{}}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+func (s *Source) WithMocks(mocks ...any) *Source {
+	clone := &Source{client: s.client, kv: s.kv, config: s.config, revision: 0, mutex: sync.Mutex{}}
+
+	for _, mock := range mocks {
+		impl, ok := mock.(clientv3.KV)
+		if ok {
+			clone.kv = impl
+
+			break
+		}
+	}
+
+	return clone
+}
+
+// Revision is the etcd revision the last extraction was read at.
+func (s *Source) Revision() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.revision
+}
+
+func (s *Source) Close() error {
+	return s.client.Close() //nolint:wrapcheck // nothing to add
+}
+
+// Extract reads the keys page by page, every page is pinned to the revision
+// of the first one, so the data is consistent.
+func (s *Source) Extract(ctx context.Context) (map[string]string, error) {
+	var (
+		envs     = make(map[string]string)
+		revision = s.config.Revision
+		key      = s.config.Prefix
+		end      = clientv3.GetPrefixRangeEnd(s.config.Prefix)
+	)
+
+	for {
+		resp, err := s.kv.Get(ctx, key,
+			clientv3.WithRange(end), clientv3.WithRev(revision), clientv3.WithLimit(pageSize),
+			clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend),
+		)
+		if err != nil {
+			return nil, ErrEtcdError.Because(err)
+		}
+
+		if revision == 0 {
+			revision = resp.Header.GetRevision()
+		}
+
+		for _, kv := range resp.Kvs {
+			envs[flatten.Key(strings.TrimPrefix(string(kv.Key), s.config.Prefix))] = string(kv.Value)
+		}
+
+		if !resp.More || len(resp.Kvs) == 0 {
+			break
+		}
+
+		key = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+
+	s.mutex.Lock()
+	s.revision = revision
+	s.mutex.Unlock()
+
+	return envs, nil
+}
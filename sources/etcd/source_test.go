@@ -0,0 +1,185 @@
+package etcd_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/etcd"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	endpoints := []string{"127.0.0.1:2379"}
+
+	tests := []struct {
+		err    error
+		config etcd.Config
+		name   string
+	}{
+		{name: "valid", config: etcd.Config{Endpoints: endpoints, Prefix: "/services/billing"}, err: nil},
+		{name: "missing endpoints", config: etcd.Config{Prefix: "billing"}, err: etcd.ErrMissingEndpoints},
+		{name: "missing prefix", config: etcd.Config{Endpoints: endpoints, Prefix: "/"}, err: etcd.ErrMissingPrefix},
+		{
+			name:   "invalid revision",
+			config: etcd.Config{Endpoints: endpoints, Prefix: "billing", Revision: -1},
+			err:    etcd.ErrInvalidRevision,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func freeURL(t *testing.T) url.URL {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+
+	return url.URL{Scheme: "http", Host: listener.Addr().String()}
+}
+
+// server starts the embedded etcd and returns its client endpoint.
+func server(t *testing.T) string {
+	t.Helper()
+
+	config := embed.NewConfig()
+	config.Dir = t.TempDir()
+	config.LogLevel = "error"
+
+	client, peer := freeURL(t), freeURL(t)
+	config.ListenClientUrls, config.AdvertiseClientUrls = []url.URL{client}, []url.URL{client}
+	config.ListenPeerUrls, config.AdvertisePeerUrls = []url.URL{peer}, []url.URL{peer}
+	config.InitialCluster = config.InitialClusterFromName(config.Name)
+
+	server, err := embed.StartEtcd(config)
+
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(time.Minute):
+		t.Fatal("etcd is not ready")
+	}
+
+	return client.Host
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	endpoint := server(t)
+
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{endpoint}, DialTimeout: time.Second})
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	put := func(key string, val string) int64 {
+		resp, err := client.Put(t.Context(), key, val)
+
+		require.NoError(t, err)
+
+		return resp.Header.GetRevision()
+	}
+
+	put("/services/billing/db/host", "db")
+	put("/services/billing/log-level", "info")
+	put("/services/billing-old/db/host", "ignored")
+	put("services/billing/region", "eu-west-1")
+
+	revision := put("/services/other/db/host", "ignored")
+
+	for i := range 600 {
+		put(fmt.Sprintf("/services/bulk/key-%03d", i), "v")
+	}
+
+	put("/services/billing/db/host", "changed")
+
+	obj, err := etcd.NewWithConfig(etcd.Config{Endpoints: []string{endpoint}, Prefix: "/services/billing"})
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = obj.Close() })
+	assert.Equal(t, "/services/billing/", obj.Config().Prefix)
+	assert.Zero(t, obj.Revision())
+
+	got, err := obj.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "changed", "LOG_LEVEL": "info"}, got)
+	assert.Greater(t, obj.Revision(), revision)
+
+	old, err := etcd.NewWithConfig(etcd.Config{
+		Endpoints: []string{endpoint}, Prefix: "/services/billing/", Revision: revision,
+	})
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = old.Close() })
+
+	got, err = old.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_HOST": "db", "LOG_LEVEL": "info"}, got)
+	assert.Equal(t, revision, old.Revision())
+
+	unslashed, err := etcd.NewWithConfig(etcd.Config{Endpoints: []string{endpoint}, Prefix: "services/billing"})
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = unslashed.Close() })
+	assert.Equal(t, "services/billing/", unslashed.Config().Prefix)
+
+	got, err = unslashed.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"REGION": "eu-west-1"}, got)
+
+	bulk, err := etcd.NewWithConfig(etcd.Config{Endpoints: []string{endpoint}, Prefix: "/services/bulk"})
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = bulk.Close() })
+
+	got, err = bulk.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Len(t, got, 600)
+}
+
+type failingKV struct {
+	clientv3.KV
+}
+
+func (failingKV) Get(context.Context, string, ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	return nil, context.DeadlineExceeded
+}
+
+func TestSourceWithMocks(t *testing.T) {
+	t.Parallel()
+
+	obj, err := etcd.NewWithConfig(etcd.Config{Endpoints: []string{"127.0.0.1:1"}, Prefix: "billing"})
+
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = obj.Close() })
+
+	got, err := obj.WithMocks(nil, failingKV{}).Extract(t.Context())
+
+	require.ErrorIs(t, err, etcd.ErrEtcdError)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, got)
+}
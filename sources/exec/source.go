@@ -0,0 +1,257 @@
+// Package exec reads the variables from the output of an external command,
+// e.g. `pass`, `op` or a company credential helper.
+//
+// The command output is parsed as dotenv or as a JSON object. Any secret
+// backend can also be plugged in with the Plugin format, a small protocol
+// over the standard streams:
+//
+//   - the command receives the request as one JSON line on stdin:
+//     {"version":1,"keys":["DB_PASSWORD","API_TOKEN"]}, the empty keys mean
+//     all the keys the plugin knows;
+//   - the command writes the response as a JSON object to stdout:
+//     {"values":{"DB_PASSWORD":"s3cr3t"}}, the unknown keys are omitted;
+//   - a failure is reported with {"error":"reason"} or with the non-zero
+//     exit code, stderr is kept for the error message.
+package exec
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultSeparator = "_"
+	defaultTimeout   = 30 * time.Second
+	stderrLimit      = 1 << 10
+
+	// ProtocolVersion is the version of the plugin request.
+	ProtocolVersion = 1
+
+	Dotenv Format = "dotenv"
+	JSON   Format = "json"
+	Plugin Format = "plugin"
+
+	ErrMissingCommand ex.Const = "missing command"
+	ErrInvalidFormat  ex.Const = "invalid format"
+	ErrCommandFailed  ex.Const = "command failed"
+	ErrInvalidOutput  ex.Const = "invalid command output"
+	ErrPluginError    ex.Const = "plugin error"
+)
+
+type (
+	Format string
+
+	// Config describes the command. Env is added to the environment of the
+	// current process, Keys are sent to the plugins.
+	Config struct {
+		Command   string
+		Format    Format
+		Dir       string
+		Separator string
+		Args      []string
+		Env       []string
+		Keys      []string
+		Timeout   time.Duration
+	}
+
+	Source struct {
+		config Config
+	}
+
+	// Request is written by the source to the plugin stdin.
+	Request struct {
+		Keys    []string `json:"keys"`
+		Version int      `json:"version"`
+	}
+
+	// Response is read by the source from the plugin stdout.
+	Response struct {
+		Values map[string]string `json:"values"`
+		Error  string            `json:"error,omitempty"`
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.Command == "" {
+		return ErrMissingCommand
+	}
+
+	switch c.Format {
+	case "", Dotenv, JSON, Plugin:
+	default:
+		return ErrInvalidFormat.Reason(string(c.Format))
+	}
+
+	return nil
+}
+
+// New runs the command and reads its output as dotenv.
+func New(command string, args ...string) (*Source, error) {
+	return NewWithConfig(Config{
+		Command:   command,
+		Format:    Dotenv,
+		Dir:       "",
+		Separator: defaultSeparator,
+		Args:      args,
+		Env:       nil,
+		Keys:      nil,
+		Timeout:   defaultTimeout,
+	})
+}
+
+// NewPlugin runs the command with the plugin protocol for the keys.
+func NewPlugin(command string, keys ...string) (*Source, error) {
+	return NewWithConfig(Config{
+		Command:   command,
+		Format:    Plugin,
+		Dir:       "",
+		Separator: defaultSeparator,
+		Args:      nil,
+		Env:       nil,
+		Keys:      keys,
+		Timeout:   defaultTimeout,
+	})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Format = cmp.Or(config.Format, Dotenv)
+	config.Separator = cmp.Or(config.Separator, defaultSeparator)
+	config.Timeout = cmp.Or(config.Timeout, defaultTimeout)
+
+	return &Source{config: config}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+func (s *Source) Extract(ctx context.Context) (map[string]string, error) {
+	var stdin []byte
+
+	if s.config.Format == Plugin {
+		keys := s.config.Keys
+		if keys == nil {
+			keys = make([]string, 0)
+		}
+
+		request, err := json.Marshal(Request{Keys: keys, Version: ProtocolVersion})
+		if err != nil {
+			return nil, ex.Unexpected(err)
+		}
+
+		stdin = append(request, '\n')
+	}
+
+	stdout, err := s.run(ctx, stdin)
+	if err != nil {
+		var response Response
+
+		// the plugins may explain the exit code in the response
+		if s.config.Format == Plugin && json.Unmarshal(stdout, &response) == nil && response.Error != "" {
+			return nil, ErrPluginError.Reason(response.Error)
+		}
+
+		return nil, err
+	}
+
+	switch s.config.Format { //nolint:exhaustive // dotenv is the default
+	case JSON:
+		return s.parseJSON(stdout)
+	case Plugin:
+		return s.parsePlugin(stdout)
+	default:
+		envs, err := godotenv.Parse(bytes.NewReader(stdout))
+		if err != nil {
+			return nil, ErrInvalidOutput.Because(err)
+		}
+
+		return envs, nil
+	}
+}
+
+func (s *Source) run(ctx context.Context, stdin []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, s.config.Command, s.config.Args...)
+	cmd.Dir = s.config.Dir
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if len(s.config.Env) != 0 {
+		cmd.Env = append(os.Environ(), s.config.Env...)
+	}
+
+	err := cmd.Run()
+	if err != nil {
+		text := strings.TrimSpace(stderr.String())
+		if len(text) > stderrLimit {
+			text = text[:stderrLimit]
+		}
+
+		if text == "" {
+			return stdout.Bytes(), ErrCommandFailed.Because(err)
+		}
+
+		return stdout.Bytes(), ErrCommandFailed.Reason(err.Error() + ": " + text)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (s *Source) parseJSON(stdout []byte) (map[string]string, error) {
+	var data map[string]any
+
+	decoder := json.NewDecoder(bytes.NewReader(stdout))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&data)
+	if err != nil {
+		return nil, ErrInvalidOutput.Because(err)
+	}
+
+	return flatten.Map(data, s.config.Separator), nil
+}
+
+func (s *Source) parsePlugin(stdout []byte) (map[string]string, error) {
+	var response Response
+
+	err := json.Unmarshal(stdout, &response)
+	if err != nil {
+		return nil, ErrInvalidOutput.Because(err)
+	}
+
+	if response.Error != "" {
+		return nil, ErrPluginError.Reason(response.Error)
+	}
+
+	envs := make(map[string]string, len(response.Values))
+
+	// the plugin must not inject the variables that were not requested
+	for key, value := range response.Values {
+		if len(s.config.Keys) == 0 || slices.Contains(s.config.Keys, key) {
+			envs[key] = value
+		}
+	}
+
+	return envs, nil
+}
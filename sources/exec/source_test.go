@@ -0,0 +1,204 @@
+package exec_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/exec"
+	"github.com/therenotomorrow/ex"
+)
+
+// plugin answers only the requests of the protocol version 1 with the keys
+// it knows, the unknown key is a failure.
+const plugin = `read -r request
+case "$request" in
+*'"version":1'*) ;;
+*) echo '{"error":"unsupported version"}'; exit 1 ;;
+esac
+case "$request" in
+*UNKNOWN*) echo "no such key" >&2; exit 2 ;;
+*'"keys":[]'*) echo '{"values":{"DB_PASSWORD":"s3cr3t","API_TOKEN":"t0ken"}}' ;;
+*) echo '{"values":{"DB_PASSWORD":"s3cr3t","API_TOKEN":"t0ken","INJECTED":"evil"}}' ;;
+esac`
+
+func pluginConfig(keys ...string) exec.Config {
+	return exec.Config{Command: "sh", Args: []string{"-c", plugin}, Format: exec.Plugin, Keys: keys}
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config exec.Config
+		name   string
+	}{
+		{name: "valid", config: exec.Config{Command: "pass"}, err: nil},
+		{name: "valid plugin", config: exec.Config{Command: "helper", Format: exec.Plugin}, err: nil},
+		{name: "missing command", config: exec.Config{}, err: exec.ErrMissingCommand},
+		{name: "invalid format", config: exec.Config{Command: "pass", Format: "xml"}, err: exec.ErrInvalidFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := exec.New("op", "inject", "-i", ".env.tpl")
+
+	require.NoError(t, err)
+	assert.Equal(t, exec.Config{
+		Command:   "op",
+		Format:    exec.Dotenv,
+		Dir:       "",
+		Separator: "_",
+		Args:      []string{"inject", "-i", ".env.tpl"},
+		Env:       nil,
+		Keys:      nil,
+		Timeout:   30 * time.Second,
+	}, obj.Config())
+
+	obj, err = exec.NewPlugin("secret-helper", "DB_PASSWORD")
+
+	require.NoError(t, err)
+	assert.Equal(t, exec.Plugin, obj.Config().Format)
+	assert.Equal(t, []string{"DB_PASSWORD"}, obj.Config().Keys)
+
+	obj, err = exec.New("")
+
+	require.ErrorIs(t, err, exec.ErrMissingCommand)
+	assert.Nil(t, obj)
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		envs map[string]string
+		err  error
+	}
+
+	tests := []struct {
+		want   want
+		name   string
+		config exec.Config
+	}{
+		{
+			name: "dotenv",
+			config: exec.Config{
+				Command: "sh", Args: []string{"-c", `printf 'DB_USER=admin\nDB_PASSWORD="s3cr3t"\n# note\n'`},
+			},
+			want: want{envs: map[string]string{"DB_USER": "admin", "DB_PASSWORD": "s3cr3t"}, err: nil},
+		},
+		{
+			name: "json",
+			config: exec.Config{
+				Command: "sh", Args: []string{"-c", `echo '{"db":{"user":"admin","port":5432},"debug":true}'`},
+				Format: exec.JSON, Separator: "__",
+			},
+			want: want{envs: map[string]string{"db__user": "admin", "db__port": "5432", "debug": "true"}, err: nil},
+		},
+		{
+			name: "env and dir",
+			config: exec.Config{
+				Command: "sh", Args: []string{"-c", `echo "TOKEN=$HELPER_TOKEN"; echo "DIR=$(pwd)"`},
+				Env: []string{"HELPER_TOKEN=t0ken"}, Dir: "/",
+			},
+			want: want{envs: map[string]string{"TOKEN": "t0ken", "DIR": "/"}, err: nil},
+		},
+		{
+			name:   "plugin",
+			config: pluginConfig("DB_PASSWORD"),
+			want:   want{envs: map[string]string{"DB_PASSWORD": "s3cr3t"}, err: nil},
+		},
+		{
+			name:   "plugin all keys",
+			config: pluginConfig(),
+			want:   want{envs: map[string]string{"DB_PASSWORD": "s3cr3t", "API_TOKEN": "t0ken"}, err: nil},
+		},
+		{
+			name:   "plugin failure",
+			config: pluginConfig("UNKNOWN"),
+			want:   want{envs: nil, err: exec.ErrCommandFailed},
+		},
+		{
+			name: "plugin error",
+			config: exec.Config{
+				Command: "sh", Args: []string{"-c", `cat > /dev/null; echo '{"error":"vault is sealed"}'`},
+				Format: exec.Plugin,
+			},
+			want: want{envs: nil, err: exec.ErrPluginError},
+		},
+		{
+			name: "plugin error with exit code",
+			config: exec.Config{
+				Command: "sh", Args: []string{"-c", `cat > /dev/null; echo '{"error":"vault is sealed"}'; exit 1`},
+				Format: exec.Plugin,
+			},
+			want: want{envs: nil, err: exec.ErrPluginError},
+		},
+		{
+			name:   "plugin invalid output",
+			config: exec.Config{Command: "sh", Args: []string{"-c", "echo values"}, Format: exec.Plugin},
+			want:   want{envs: nil, err: exec.ErrInvalidOutput},
+		},
+		{
+			name:   "invalid json",
+			config: exec.Config{Command: "sh", Args: []string{"-c", "echo '[1, 2]'"}, Format: exec.JSON},
+			want:   want{envs: nil, err: exec.ErrInvalidOutput},
+		},
+		{
+			name:   "invalid dotenv",
+			config: exec.Config{Command: "sh", Args: []string{"-c", `echo 'KEY="unterminated'`}},
+			want:   want{envs: nil, err: exec.ErrInvalidOutput},
+		},
+		{
+			name:   "exit code",
+			config: exec.Config{Command: "sh", Args: []string{"-c", "exit 3"}},
+			want:   want{envs: nil, err: exec.ErrCommandFailed},
+		},
+		{
+			name:   "timeout",
+			config: exec.Config{Command: "sleep", Args: []string{"10"}, Timeout: 10 * time.Millisecond},
+			want:   want{envs: nil, err: exec.ErrCommandFailed},
+		},
+		{
+			name:   "not found",
+			config: exec.Config{Command: "enw-not-exist-helper"},
+			want:   want{envs: nil, err: exec.ErrCommandFailed},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(exec.NewWithConfig(test.config))
+
+			envs, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.envs, envs)
+		})
+	}
+}
+
+func TestSourceExtractStderr(t *testing.T) {
+	t.Parallel()
+
+	obj := ex.Must(exec.NewWithConfig(pluginConfig("UNKNOWN")))
+
+	_, err := obj.Extract(t.Context())
+
+	require.ErrorIs(t, err, exec.ErrCommandFailed)
+	assert.ErrorContains(t, err, "no such key")
+}
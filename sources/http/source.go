@@ -0,0 +1,309 @@
+// Package http reads the variables from a JSON document served over HTTP.
+// The response ETag is remembered, so the periodic reloads of the unchanged
+// document cost only the `304 Not Modified` answer.
+package http
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultSeparator = "_"
+	defaultTimeout   = 10 * time.Second
+
+	headerETag        = "ETag"
+	headerIfNoneMatch = "If-None-Match"
+	headerAccept      = "Accept"
+
+	ErrMissingURL       ex.Const = "missing url"
+	ErrInvalidURL       ex.Const = "invalid url"
+	ErrInvalidPointer   ex.Const = "invalid json pointer"
+	ErrInvalidTLS       ex.Const = "invalid tls options"
+	ErrPointerNotFound  ex.Const = "json pointer not found"
+	ErrNotObject        ex.Const = "json value is not an object"
+	ErrEndpointError    ex.Const = "endpoint error"
+	ErrMissingPassword  ex.Const = "missing password for the basic auth"
+	ErrConflictingAuths ex.Const = "either token or basic auth can be used"
+)
+
+type (
+	// Config describes the request. Pointer is the RFC 6901 JSON pointer to
+	// the object with the variables, the nested keys of that object are
+	// joined with the separator.
+	Config struct {
+		Headers   map[string]string
+		URL       string
+		Token     string
+		Username  string
+		Password  string
+		Pointer   string
+		Separator string
+		CAFile    string
+		CertFile  string
+		KeyFile   string
+		Timeout   time.Duration
+		Insecure  bool
+	}
+
+	Source struct {
+		client *http.Client
+		envs   map[string]string
+		etag   string
+		config Config
+		mutex  sync.Mutex
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.URL == "" {
+		return ErrMissingURL
+	}
+
+	parsed, err := url.Parse(c.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL.Reason(c.URL)
+	}
+
+	if c.Pointer != "" && !strings.HasPrefix(c.Pointer, "/") {
+		return ErrInvalidPointer.Reason(c.Pointer)
+	}
+
+	if c.Username != "" && c.Password == "" {
+		return ErrMissingPassword
+	}
+
+	if c.Token != "" && c.Username != "" {
+		return ErrConflictingAuths
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return ErrInvalidTLS.Reason("both cert and key files are required")
+	}
+
+	return nil
+}
+
+func New(endpoint string) (*Source, error) {
+	return NewWithConfig(Config{
+		Headers:   nil,
+		URL:       endpoint,
+		Token:     "",
+		Username:  "",
+		Password:  "",
+		Pointer:   "",
+		Separator: defaultSeparator,
+		CAFile:    "",
+		CertFile:  "",
+		KeyFile:   "",
+		Timeout:   defaultTimeout,
+		Insecure:  false,
+	})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Separator = cmp.Or(config.Separator, defaultSeparator)
+	config.Timeout = cmp.Or(config.Timeout, defaultTimeout)
+
+	transport, err := config.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{
+		client: &http.Client{Transport: transport, Timeout: config.Timeout}, //nolint:exhaustruct // defaults
+		envs:   nil,
+		etag:   "",
+		config: config,
+		mutex:  sync.Mutex{},
+	}, nil
+}
+
+func (c *Config) transport() (*http.Transport, error) {
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+
+	//nolint:exhaustruct // the rest are the defaults
+	options := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.Insecure, //nolint:gosec // asked explicitly, for the local endpoints
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, ErrInvalidTLS.Because(err)
+		}
+
+		options.RootCAs = x509.NewCertPool()
+		if !options.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, ErrInvalidTLS.Reason("no certificates in " + c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, ErrInvalidTLS.Because(err)
+		}
+
+		options.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = options
+
+	return transport, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+// ETag is the entity tag of the last extracted document.
+func (s *Source) ETag() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.etag
+}
+
+// Extract sends the remembered ETag, so the document is downloaded and
+// parsed again only when it has changed.
+func (s *Source) Extract(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.URL, nil)
+	if err != nil {
+		return nil, ErrEndpointError.Because(err)
+	}
+
+	req.Header.Set(headerAccept, "application/json")
+
+	for name, value := range s.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	switch {
+	case s.config.Token != "":
+		req.Header.Set("Authorization", "Bearer "+s.config.Token)
+	case s.config.Username != "":
+		req.SetBasicAuth(s.config.Username, s.config.Password)
+	}
+
+	if etag := s.ETag(); etag != "" {
+		req.Header.Set(headerIfNoneMatch, etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, ErrEndpointError.Because(err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		return maps.Clone(s.envs), nil
+	case resp.StatusCode >= http.StatusBadRequest:
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint:mnd // enough for the errors
+
+		return nil, ErrEndpointError.Reason(resp.Status + ": " + strings.TrimSpace(string(text)))
+	}
+
+	envs, err := s.decode(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.envs = envs
+	s.etag = resp.Header.Get(headerETag)
+
+	return maps.Clone(envs), nil
+}
+
+func (s *Source) decode(body io.Reader) (map[string]string, error) {
+	var doc any
+
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	err := decoder.Decode(&doc)
+	if err != nil {
+		return nil, ErrEndpointError.Because(err)
+	}
+
+	value, err := Pointer(doc, s.config.Pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, ErrNotObject.Reason(cmp.Or(s.config.Pointer, "/"))
+	}
+
+	return flatten.Map(object, s.config.Separator), nil
+}
+
+// Pointer resolves the RFC 6901 JSON pointer in the decoded document,
+// the empty pointer is the whole document.
+func Pointer(doc any, pointer string) (any, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, ErrInvalidPointer.Reason(pointer)
+	}
+
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+
+	for token := range strings.SplitSeq(pointer[1:], "/") {
+		token = unescape.Replace(token)
+
+		switch typed := doc.(type) {
+		case map[string]any:
+			value, ok := typed[token]
+			if !ok {
+				return nil, ErrPointerNotFound.Reason(pointer)
+			}
+
+			doc = value
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(typed) || (len(token) > 1 && token[0] == '0') {
+				return nil, ErrPointerNotFound.Reason(pointer)
+			}
+
+			doc = typed[index]
+		default:
+			return nil, ErrPointerNotFound.Reason(pointer)
+		}
+	}
+
+	return doc, nil
+}
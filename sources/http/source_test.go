@@ -0,0 +1,372 @@
+package http_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	enwhttp "github.com/therenotomorrow/enw/sources/http"
+	"github.com/therenotomorrow/ex"
+)
+
+const document = `{
+	"service": "billing",
+	"config": {"db": {"host": "localhost", "port": 5432}, "debug": true, "hosts": ["a", "b"]},
+	"list": [{"a~b/c": {"KEY": "value"}}]
+}`
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config enwhttp.Config
+		name   string
+	}{
+		{name: "valid", config: enwhttp.Config{URL: "https://config.local/v1"}, err: nil},
+		{name: "missing url", config: enwhttp.Config{}, err: enwhttp.ErrMissingURL},
+		{name: "invalid scheme", config: enwhttp.Config{URL: "ftp://config.local"}, err: enwhttp.ErrInvalidURL},
+		{name: "missing host", config: enwhttp.Config{URL: "http://"}, err: enwhttp.ErrInvalidURL},
+		{
+			name:   "invalid pointer",
+			config: enwhttp.Config{URL: "http://config.local", Pointer: "config"},
+			err:    enwhttp.ErrInvalidPointer,
+		},
+		{
+			name:   "missing password",
+			config: enwhttp.Config{URL: "http://config.local", Username: "admin"},
+			err:    enwhttp.ErrMissingPassword,
+		},
+		{
+			name:   "conflicting auths",
+			config: enwhttp.Config{URL: "http://config.local", Username: "admin", Password: "pass", Token: "token"},
+			err:    enwhttp.ErrConflictingAuths,
+		},
+		{
+			name:   "cert without key",
+			config: enwhttp.Config{URL: "http://config.local", CertFile: "client.pem"},
+			err:    enwhttp.ErrInvalidTLS,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := enwhttp.New("http://config.local/v1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "_", obj.Config().Separator)
+	assert.Equal(t, 10*time.Second, obj.Config().Timeout)
+
+	obj, err = enwhttp.New("")
+
+	require.ErrorIs(t, err, enwhttp.ErrMissingURL)
+	assert.Nil(t, obj)
+}
+
+func TestNewWithConfigTLS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config enwhttp.Config
+		name   string
+	}{
+		{
+			name:   "missing ca file",
+			config: enwhttp.Config{URL: "https://config.local", CAFile: "not-exist.pem"},
+			err:    enwhttp.ErrInvalidTLS,
+		},
+		{
+			name:   "empty ca file",
+			config: enwhttp.Config{URL: "https://config.local", CAFile: testFile(t, "ca.pem", nil)},
+			err:    enwhttp.ErrInvalidTLS,
+		},
+		{
+			name:   "missing key pair",
+			config: enwhttp.Config{URL: "https://config.local", CertFile: "cert.pem", KeyFile: "key.pem"},
+			err:    enwhttp.ErrInvalidTLS,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := enwhttp.NewWithConfig(test.config)
+
+			require.ErrorIs(t, err, test.err)
+			assert.Nil(t, obj)
+		})
+	}
+}
+
+func TestPointer(t *testing.T) {
+	t.Parallel()
+
+	doc := map[string]any{
+		"a":   map[string]any{"b": "c"},
+		"m~n": "tilde",
+		"x/y": "slash",
+		"l":   []any{"zero", map[string]any{"k": "v"}},
+	}
+
+	tests := []struct {
+		want    any
+		err     error
+		name    string
+		pointer string
+	}{
+		{name: "whole", pointer: "", want: doc, err: nil},
+		{name: "nested", pointer: "/a/b", want: "c", err: nil},
+		{name: "tilde", pointer: "/m~0n", want: "tilde", err: nil},
+		{name: "slash", pointer: "/x~1y", want: "slash", err: nil},
+		{name: "index", pointer: "/l/1/k", want: "v", err: nil},
+		{name: "missing key", pointer: "/a/z", want: nil, err: enwhttp.ErrPointerNotFound},
+		{name: "out of range", pointer: "/l/2", want: nil, err: enwhttp.ErrPointerNotFound},
+		{name: "leading zero", pointer: "/l/01", want: nil, err: enwhttp.ErrPointerNotFound},
+		{name: "into scalar", pointer: "/a/b/c", want: nil, err: enwhttp.ErrPointerNotFound},
+		{name: "relative", pointer: "a", want: nil, err: enwhttp.ErrInvalidPointer},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := enwhttp.Pointer(doc, test.pointer)
+
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func testFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+
+	name = filepath.Join(t.TempDir(), name)
+
+	ex.MustDo(os.WriteFile(name, content, 0o600))
+
+	return name
+}
+
+// server answers the document for the expected credentials and honors
+// the `If-None-Match` header, the downloads are counted.
+func server(t *testing.T, downloads *atomic.Int64) *httptest.Server {
+	t.Helper()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, basic := r.BasicAuth()
+
+		switch {
+		case r.URL.Path == "/broken":
+			_, _ = w.Write([]byte("{"))
+
+			return
+		case r.URL.Path == "/fail":
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+
+			return
+		case basic && (user != "admin" || pass != "pass"):
+			http.Error(w, "wrong credentials", http.StatusUnauthorized)
+
+			return
+		case !basic && r.Header.Get("Authorization") != "Bearer token":
+			http.Error(w, "missing token", http.StatusUnauthorized)
+
+			return
+		case r.Header.Get("X-Team") != "billing":
+			http.Error(w, "missing team", http.StatusForbidden)
+
+			return
+		case r.Header.Get("If-None-Match") == `"v1"`:
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		downloads.Add(1)
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(document))
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int64
+
+	srv := server(t, &downloads)
+	headers := map[string]string{"X-Team": "billing"}
+
+	type want struct {
+		envs map[string]string
+		err  error
+	}
+
+	tests := []struct {
+		want   want
+		name   string
+		config enwhttp.Config
+	}{
+		{
+			name:   "whole document",
+			config: enwhttp.Config{URL: srv.URL, Token: "token", Headers: headers},
+			want: want{
+				envs: map[string]string{
+					"service": "billing", "config_db_host": "localhost", "config_db_port": "5432",
+					"config_debug": "true", "config_hosts": `["a","b"]`, "list": `[{"a~b/c":{"KEY":"value"}}]`,
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "pointer and separator",
+			config: enwhttp.Config{
+				URL: srv.URL, Username: "admin", Password: "pass", Headers: headers,
+				Pointer: "/config", Separator: "__",
+			},
+			want: want{
+				envs: map[string]string{
+					"db__host": "localhost", "db__port": "5432", "debug": "true", "hosts": `["a","b"]`,
+				},
+				err: nil,
+			},
+		},
+		{
+			name:   "escaped pointer",
+			config: enwhttp.Config{URL: srv.URL, Token: "token", Headers: headers, Pointer: "/list/0/a~0b~1c"},
+			want:   want{envs: map[string]string{"KEY": "value"}, err: nil},
+		},
+		{
+			name:   "pointer not found",
+			config: enwhttp.Config{URL: srv.URL, Token: "token", Headers: headers, Pointer: "/secrets"},
+			want:   want{envs: nil, err: enwhttp.ErrPointerNotFound},
+		},
+		{
+			name:   "not object",
+			config: enwhttp.Config{URL: srv.URL, Token: "token", Headers: headers, Pointer: "/service"},
+			want:   want{envs: nil, err: enwhttp.ErrNotObject},
+		},
+		{
+			name:   "wrong credentials",
+			config: enwhttp.Config{URL: srv.URL, Username: "admin", Password: "wrong", Headers: headers},
+			want:   want{envs: nil, err: enwhttp.ErrEndpointError},
+		},
+		{
+			name:   "missing header",
+			config: enwhttp.Config{URL: srv.URL, Token: "token"},
+			want:   want{envs: nil, err: enwhttp.ErrEndpointError},
+		},
+		{
+			name:   "server error",
+			config: enwhttp.Config{URL: srv.URL + "/fail"},
+			want:   want{envs: nil, err: enwhttp.ErrEndpointError},
+		},
+		{
+			name:   "broken json",
+			config: enwhttp.Config{URL: srv.URL + "/broken"},
+			want:   want{envs: nil, err: enwhttp.ErrEndpointError},
+		},
+		{
+			name:   "unavailable",
+			config: enwhttp.Config{URL: "http://127.0.0.1:1"},
+			want:   want{envs: nil, err: enwhttp.ErrEndpointError},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(enwhttp.NewWithConfig(test.config))
+
+			envs, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.envs, envs)
+		})
+	}
+}
+
+func TestSourceExtractNotModified(t *testing.T) {
+	t.Parallel()
+
+	var downloads atomic.Int64
+
+	srv := server(t, &downloads)
+	obj := ex.Must(enwhttp.NewWithConfig(enwhttp.Config{
+		URL: srv.URL, Token: "token", Headers: map[string]string{"X-Team": "billing"}, Pointer: "/config/db",
+	}))
+
+	assert.Empty(t, obj.ETag())
+
+	for range 3 {
+		envs, err := obj.Extract(t.Context())
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"host": "localhost", "port": "5432"}, envs)
+
+		// the callers may change the result, the cached one stays the same
+		envs["host"] = "changed"
+	}
+
+	assert.Equal(t, `"v1"`, obj.ETag())
+	assert.Equal(t, int64(1), downloads.Load())
+}
+
+func TestSourceExtractTLS(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"KEY": "value"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	ca := testFile(t, "ca.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	tests := []struct {
+		err    error
+		config enwhttp.Config
+		name   string
+	}{
+		{name: "custom ca", config: enwhttp.Config{URL: srv.URL, CAFile: ca}, err: nil},
+		{name: "insecure", config: enwhttp.Config{URL: srv.URL, Insecure: true}, err: nil},
+		{name: "unknown authority", config: enwhttp.Config{URL: srv.URL}, err: enwhttp.ErrEndpointError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(enwhttp.NewWithConfig(test.config))
+
+			_, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Package proc reads the environment of a running process from procfs,
+// so what the process actually got can be compared with the config.
+//
+// The environment is the one the process was started with, the later
+// changes made by the process itself are not visible in procfs.
+package proc
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultRoot = "/proc"
+
+	fileEnviron = "environ"
+	fileCmdline = "cmdline"
+
+	ErrMissingProcess     ex.Const = "missing process, either pid or command is required"
+	ErrConflictingProcess ex.Const = "either pid or command can be used"
+	ErrInvalidPID         ex.Const = "invalid pid"
+	ErrInvalidCommand     ex.Const = "invalid command pattern"
+	ErrProcessNotFound    ex.Const = "process not found"
+	ErrAmbiguousProcess   ex.Const = "command matches several processes"
+	ErrPermissionDenied   ex.Const = "permission denied, run as the process owner or with CAP_SYS_PTRACE"
+)
+
+type (
+	// Config selects the process by PID or by the regular expression over
+	// its command line, the arguments are joined with spaces.
+	Config struct {
+		Command string
+		Root    string
+		PID     int
+	}
+
+	Source struct {
+		command *regexp.Regexp
+		config  Config
+	}
+)
+
+func (c *Config) Validate() error {
+	switch {
+	case c.PID < 0:
+		return ErrInvalidPID.Reason(strconv.Itoa(c.PID))
+	case c.PID == 0 && c.Command == "":
+		return ErrMissingProcess
+	case c.PID != 0 && c.Command != "":
+		return ErrConflictingProcess
+	}
+
+	return nil
+}
+
+func New(pid int) (*Source, error) {
+	return NewWithConfig(Config{Command: "", Root: defaultRoot, PID: pid})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Root = cmp.Or(config.Root, defaultRoot)
+
+	var command *regexp.Regexp
+
+	if config.Command != "" {
+		command, err = regexp.Compile(config.Command)
+		if err != nil {
+			return nil, ErrInvalidCommand.Because(err)
+		}
+	}
+
+	return &Source{command: command, config: config}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+func (s *Source) Extract(_ context.Context) (map[string]string, error) {
+	pid := s.config.PID
+
+	if s.command != nil {
+		var err error
+
+		pid, err = s.find()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := s.environ(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data), nil
+}
+
+// Parse splits the NUL-separated `KEY=value` entries, the entries without
+// the `=` are skipped and the first duplicate wins as in `getenv`.
+func Parse(data []byte) map[string]string {
+	envs := make(map[string]string)
+
+	for entry := range bytes.SplitSeq(data, []byte{0}) {
+		key, val, ok := strings.Cut(string(entry), "=")
+		if _, seen := envs[key]; ok && key != "" && !seen {
+			envs[key] = val
+		}
+	}
+
+	return envs
+}
+
+// find looks for the only process with the matching command line, the
+// current process is skipped because its arguments may hold the pattern.
+func (s *Source) find() (int, error) {
+	entries, err := os.ReadDir(s.config.Root)
+	if err != nil {
+		return 0, ex.Unexpected(err)
+	}
+
+	found := make([]int, 0)
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() || pid == os.Getpid() {
+			continue
+		}
+
+		// kernel threads have no command line and the processes may exit
+		data, err := os.ReadFile(filepath.Join(s.config.Root, entry.Name(), fileCmdline))
+		if err != nil || len(data) == 0 {
+			continue
+		}
+
+		cmdline := strings.ReplaceAll(strings.TrimRight(string(data), "\x00"), "\x00", " ")
+		if s.command.MatchString(cmdline) {
+			found = append(found, pid)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return 0, ErrProcessNotFound.Reason(s.config.Command)
+	case 1:
+		return found[0], nil
+	default:
+		slices.Sort(found)
+
+		pids := make([]string, 0, len(found))
+		for _, pid := range found {
+			pids = append(pids, strconv.Itoa(pid))
+		}
+
+		return 0, ErrAmbiguousProcess.Reason(strings.Join(pids, ", "))
+	}
+}
+
+func (s *Source) environ(pid int) ([]byte, error) {
+	path := filepath.Join(s.config.Root, strconv.Itoa(pid), fileEnviron)
+
+	data, err := os.ReadFile(path)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, ErrProcessNotFound.Reason(strconv.Itoa(pid))
+	case errors.Is(err, fs.ErrPermission):
+		return nil, ErrPermissionDenied.Reason(path)
+	case err != nil:
+		return nil, ex.Unexpected(err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,202 @@
+package proc_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/proc"
+	"github.com/therenotomorrow/ex"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config proc.Config
+		name   string
+	}{
+		{name: "pid", config: proc.Config{PID: 1}, err: nil},
+		{name: "command", config: proc.Config{Command: "billing"}, err: nil},
+		{name: "missing process", config: proc.Config{}, err: proc.ErrMissingProcess},
+		{name: "negative pid", config: proc.Config{PID: -1}, err: proc.ErrInvalidPID},
+		{name: "both", config: proc.Config{PID: 1, Command: "billing"}, err: proc.ErrConflictingProcess},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := proc.New(42)
+
+	require.NoError(t, err)
+	assert.Equal(t, proc.Config{Command: "", Root: "/proc", PID: 42}, obj.Config())
+
+	obj, err = proc.NewWithConfig(proc.Config{Command: "billing("})
+
+	require.ErrorIs(t, err, proc.ErrInvalidCommand)
+	assert.Nil(t, obj)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	got := proc.Parse([]byte("A=1\x00B=x=y\x00EMPTY=\x00BROKEN\x00=nokey\x00A=2\x00"))
+
+	assert.Equal(t, map[string]string{"A": "1", "B": "x=y", "EMPTY": ""}, got)
+}
+
+// testRoot builds the fake procfs: the pid, its command line and environ.
+func testRoot(t *testing.T, processes map[int][2]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	for pid, files := range processes {
+		dir := filepath.Join(root, strconv.Itoa(pid))
+
+		ex.MustDo(os.Mkdir(dir, 0o700))
+		ex.MustDo(os.WriteFile(filepath.Join(dir, "cmdline"), []byte(files[0]), 0o600))
+
+		if files[1] != "" {
+			ex.MustDo(os.WriteFile(filepath.Join(dir, "environ"), []byte(files[1]), 0o600))
+		}
+	}
+
+	ex.MustDo(os.Mkdir(filepath.Join(root, "self"), 0o700))
+
+	return root
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	root := testRoot(t, map[int][2]string{
+		1:   {"/sbin/init\x00", "HOME=/\x00TERM=linux\x00"},
+		2:   {"", "KTHREAD=1\x00"},
+		100: {"/usr/bin/billing\x00--config\x00/etc/billing.yaml\x00", "DB_HOST=db\x00DB_PORT=5432\x00"},
+		200: {"/usr/bin/worker\x00--queue\x00emails\x00", "QUEUE=emails\x00"},
+		201: {"/usr/bin/worker\x00--queue\x00reports\x00", "QUEUE=reports\x00"},
+		300: {"/usr/bin/zombie\x00", ""},
+	})
+
+	type want struct {
+		envs map[string]string
+		err  error
+	}
+
+	tests := []struct {
+		want   want
+		name   string
+		config proc.Config
+	}{
+		{
+			name:   "by pid",
+			config: proc.Config{Root: root, PID: 1},
+			want:   want{envs: map[string]string{"HOME": "/", "TERM": "linux"}, err: nil},
+		},
+		{
+			name:   "by command",
+			config: proc.Config{Root: root, Command: `billing --config \S+\.yaml`},
+			want:   want{envs: map[string]string{"DB_HOST": "db", "DB_PORT": "5432"}, err: nil},
+		},
+		{
+			name:   "by arguments",
+			config: proc.Config{Root: root, Command: "--queue reports$"},
+			want:   want{envs: map[string]string{"QUEUE": "reports"}, err: nil},
+		},
+		{
+			name:   "ambiguous",
+			config: proc.Config{Root: root, Command: "worker"},
+			want:   want{envs: nil, err: proc.ErrAmbiguousProcess},
+		},
+		{
+			name:   "kernel thread is skipped",
+			config: proc.Config{Root: root, Command: "^$"},
+			want:   want{envs: nil, err: proc.ErrProcessNotFound},
+		},
+		{
+			name:   "command not found",
+			config: proc.Config{Root: root, Command: "scheduler"},
+			want:   want{envs: nil, err: proc.ErrProcessNotFound},
+		},
+		{
+			name:   "pid not found",
+			config: proc.Config{Root: root, PID: 404},
+			want:   want{envs: nil, err: proc.ErrProcessNotFound},
+		},
+		{
+			name:   "missing environ",
+			config: proc.Config{Root: root, Command: "zombie"},
+			want:   want{envs: nil, err: proc.ErrProcessNotFound},
+		},
+		{
+			name:   "missing root",
+			config: proc.Config{Root: filepath.Join(root, "not-exist"), Command: "billing"},
+			want:   want{envs: nil, err: ex.ErrUnexpected},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(proc.NewWithConfig(test.config))
+
+			envs, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.envs, envs)
+		})
+	}
+}
+
+func TestSourceExtractPermission(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("root reads any file")
+	}
+
+	root := testRoot(t, map[int][2]string{1: {"/sbin/init\x00", "HOME=/\x00"}})
+
+	ex.MustDo(os.Chmod(filepath.Join(root, "1", "environ"), 0o000))
+
+	obj := ex.Must(proc.NewWithConfig(proc.Config{Root: root, PID: 1}))
+
+	envs, err := obj.Extract(t.Context())
+
+	require.ErrorIs(t, err, proc.ErrPermissionDenied)
+	assert.Nil(t, envs)
+}
+
+func TestSourceExtractSelf(t *testing.T) {
+	t.Parallel()
+
+	if _, err := os.Stat("/proc/self/environ"); err != nil {
+		t.Skip("procfs is not available")
+	}
+
+	obj := ex.Must(proc.New(os.Getpid()))
+
+	envs, err := obj.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, envs)
+
+	for key, val := range envs {
+		assert.Equal(t, val, os.Getenv(key), key)
+	}
+}
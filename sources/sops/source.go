@@ -0,0 +1,248 @@
+// Package sops reads the files encrypted by SOPS with age keys. The values
+// are decrypted only in memory and the file is verified with its MAC, so
+// a tampered or partially re-encrypted file is refused.
+package sops
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultSeparator     = "_"
+	defaultIdentitiesEnv = "SOPS_AGE_KEY"
+	identitiesFileEnv    = "SOPS_AGE_KEY_FILE"
+
+	Dotenv Format = "dotenv"
+	YAML   Format = "yaml"
+	JSON   Format = "json"
+
+	ErrMissingFilename   ex.Const = "missing filename"
+	ErrMissingFile       ex.Const = "missing file"
+	ErrInvalidFormat     ex.Const = "invalid format"
+	ErrMissingIdentities ex.Const = "missing identities"
+	ErrInvalidIdentities ex.Const = "invalid identities"
+	ErrNotEncrypted      ex.Const = "file is not encrypted by sops"
+	ErrUnsupportedKeys   ex.Const = "unsupported key groups, only age keys without shamir threshold"
+	ErrNoMatchingKey     ex.Const = "no identity matches the file recipients"
+	ErrDecryptValue      ex.Const = "cannot decrypt value"
+	ErrMACMismatch       ex.Const = "mac mismatch, the file was modified"
+)
+
+type (
+	Format string
+
+	// Config describes where the file and the age identities are.
+	// Identities are read from the IdentitiesFile and from the variable
+	// named by IdentitiesEnv, the same way `sops` does it.
+	Config struct {
+		Filename       string
+		Format         Format
+		IdentitiesFile string
+		IdentitiesEnv  string
+		Separator      string
+	}
+
+	Source struct {
+		keys   map[string]bool
+		config Config
+		mutex  sync.Mutex
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.Filename == "" {
+		return ErrMissingFilename
+	}
+
+	switch c.Format {
+	case "", Dotenv, YAML, JSON:
+	default:
+		return ErrInvalidFormat.Reason(string(c.Format))
+	}
+
+	return nil
+}
+
+// New reads the file with the identities from `SOPS_AGE_KEY_FILE` and
+// `SOPS_AGE_KEY`, the format is detected by the file extension.
+func New(filename string) (*Source, error) {
+	return NewWithConfig(Config{
+		Filename:       filename,
+		Format:         "",
+		IdentitiesFile: os.Getenv(identitiesFileEnv),
+		IdentitiesEnv:  defaultIdentitiesEnv,
+		Separator:      defaultSeparator,
+	})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Format == "" {
+		config.Format, err = detect(config.Filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config.Separator = cmp.Or(config.Separator, defaultSeparator)
+
+	return &Source{keys: make(map[string]bool), config: config, mutex: sync.Mutex{}}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+// Extract decrypts the file and verifies its MAC before any value is
+// returned. Nested keys of YAML and JSON are joined with the separator.
+func (s *Source) Extract(_ context.Context) (map[string]string, error) {
+	identities, err := s.identities()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(s.config.Filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrMissingFile
+	}
+
+	if err != nil {
+		return nil, ex.Unexpected(err)
+	}
+
+	doc, err := parse(data, s.config.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := doc.meta.dataKey(identities)
+	if err != nil {
+		return nil, err
+	}
+
+	trees, err := doc.decrypt(key)
+	if err != nil {
+		return nil, err
+	}
+
+	envs := make(map[string]string)
+	for _, tree := range trees {
+		maps.Copy(envs, flatten.Map(tree, s.config.Separator))
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.keys = make(map[string]bool, len(envs))
+	for name := range envs {
+		s.keys[name] = true
+	}
+
+	return envs, nil
+}
+
+// Sensitive reports the variables that came from the last extraction,
+// it fits `diff.Config.Sensitive` and `debug.Config.Sensitive`.
+func (s *Source) Sensitive(env *enw.Env) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.keys[env.Var]
+}
+
+func (s *Source) identities() ([]age.Identity, error) {
+	var text bytes.Buffer
+
+	if s.config.IdentitiesFile != "" {
+		data, err := os.ReadFile(s.config.IdentitiesFile)
+		if err != nil {
+			return nil, ErrInvalidIdentities.Because(err)
+		}
+
+		text.Write(data)
+		text.WriteString("\n")
+	}
+
+	if s.config.IdentitiesEnv != "" {
+		text.WriteString(os.Getenv(s.config.IdentitiesEnv))
+	}
+
+	if strings.TrimSpace(text.String()) == "" {
+		return nil, ErrMissingIdentities
+	}
+
+	identities, err := age.ParseIdentities(&text)
+	if err != nil {
+		return nil, ErrInvalidIdentities.Because(err)
+	}
+
+	return identities, nil
+}
+
+func (m *metadata) dataKey(identities []age.Identity) ([]byte, error) {
+	if m.ShamirThreshold > 1 || len(m.KeyGroups) > 1 {
+		return nil, ErrUnsupportedKeys
+	}
+
+	encrypted := m.ages()
+	if len(encrypted) == 0 {
+		return nil, ErrUnsupportedKeys
+	}
+
+	var last error
+
+	for _, enc := range encrypted {
+		reader, err := age.Decrypt(armor.NewReader(strings.NewReader(enc)), identities...)
+		if err != nil {
+			last = err
+
+			continue
+		}
+
+		key, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, ex.Unexpected(err)
+		}
+
+		return key, nil
+	}
+
+	return nil, ErrNoMatchingKey.Because(last)
+}
+
+func detect(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".env":
+		return Dotenv, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	}
+
+	// `.env.prod` and `secrets.env.enc` are still dotenv
+	if strings.Contains(filepath.Base(filename), ".env") {
+		return Dotenv, nil
+	}
+
+	return "", ErrInvalidFormat.Reason(filename)
+}
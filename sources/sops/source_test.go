@@ -0,0 +1,282 @@
+package sops_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw"
+	"github.com/therenotomorrow/enw/sources/sops"
+	"github.com/therenotomorrow/ex"
+)
+
+// the fixtures are encrypted by `sops` 3.10.2 for the key from keys.txt
+const keys = "testdata/keys.txt"
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config sops.Config
+		name   string
+	}{
+		{name: "valid", config: sops.Config{Filename: "secrets.enc.env"}, err: nil},
+		{name: "valid format", config: sops.Config{Filename: "secrets", Format: sops.YAML}, err: nil},
+		{name: "missing filename", config: sops.Config{}, err: sops.ErrMissingFilename},
+		{name: "invalid format", config: sops.Config{Filename: "a.ini", Format: "ini"}, err: sops.ErrInvalidFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY_FILE", keys)
+
+	obj, err := sops.New("testdata/secrets.enc.env")
+
+	require.NoError(t, err)
+	assert.Equal(t, sops.Config{
+		Filename:       "testdata/secrets.enc.env",
+		Format:         sops.Dotenv,
+		IdentitiesFile: keys,
+		IdentitiesEnv:  "SOPS_AGE_KEY",
+		Separator:      "_",
+	}, obj.Config())
+}
+
+func TestNewWithConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err      error
+		name     string
+		filename string
+		want     sops.Format
+	}{
+		{name: "dotenv", filename: "secrets.enc.env", want: sops.Dotenv, err: nil},
+		{name: "dotenv suffix", filename: "config/.env.production", want: sops.Dotenv, err: nil},
+		{name: "yaml", filename: "secrets.enc.yaml", want: sops.YAML, err: nil},
+		{name: "yml", filename: "secrets.YML", want: sops.YAML, err: nil},
+		{name: "json", filename: "secrets.enc.json", want: sops.JSON, err: nil},
+		{name: "unknown", filename: "secrets.ini", want: "", err: sops.ErrInvalidFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj, err := sops.NewWithConfig(sops.Config{Filename: test.filename})
+
+			require.ErrorIs(t, err, test.err)
+
+			if test.err == nil {
+				assert.Equal(t, test.want, obj.Config().Format)
+				assert.Equal(t, "_", obj.Config().Separator)
+			}
+		})
+	}
+}
+
+func testFile(t *testing.T, fixture string, replace ...string) string {
+	t.Helper()
+
+	data := ex.Must(os.ReadFile(filepath.Join("testdata", fixture)))
+	name := filepath.Join(t.TempDir(), fixture)
+
+	ex.MustDo(os.WriteFile(name, []byte(strings.NewReplacer(replace...).Replace(string(data))), 0o600))
+
+	return name
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	type want struct {
+		envs map[string]string
+		err  error
+	}
+
+	stranger := ex.Must(age.GenerateX25519Identity()).String()
+
+	tests := []struct {
+		want       want
+		name       string
+		filename   string
+		identities string
+		separator  string
+	}{
+		{
+			name:       "dotenv",
+			filename:   "testdata/secrets.enc.env",
+			identities: keys,
+			want: want{
+				envs: map[string]string{
+					"DB_USER": "admin", "DB_PASSWORD": "s3cr3t", "MULTI": "line1\nline2", "EMPTY": "",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:       "yaml",
+			filename:   "testdata/secrets.enc.yaml",
+			identities: keys,
+			want: want{
+				envs: map[string]string{
+					"db_host": "localhost", "db_port": "5432", "db_enabled": "true", "db_tags": `["a","b"]`,
+					"api_key": "abc", "plain_unencrypted": "visible",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:       "json with separator",
+			filename:   "testdata/secrets.enc.json",
+			identities: keys,
+			separator:  "__",
+			want: want{
+				envs: map[string]string{
+					"db__host": "localhost", "db__port": "5432", "db__ratio": "0.5", "token": "xyz", "debug": "false",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:       "mac only encrypted",
+			filename:   "testdata/partial.enc.yaml",
+			identities: keys,
+			want:       want{envs: map[string]string{"api_key": "abc", "region": "eu"}, err: nil},
+		},
+		{
+			name:       "modified plain value",
+			filename:   testFile(t, "secrets.enc.yaml", "plain_unencrypted: visible", "plain_unencrypted: hidden"),
+			identities: keys,
+			want:       want{envs: nil, err: sops.ErrMACMismatch},
+		},
+		{
+			name:       "swapped values",
+			filename:   testFile(t, "secrets.enc.env", "DB_USER=", "DB_PASSWORD=", "DB_PASSWORD=", "DB_USER="),
+			identities: keys,
+			want:       want{envs: nil, err: sops.ErrDecryptValue},
+		},
+		{
+			name:       "stranger identity",
+			filename:   "testdata/secrets.enc.json",
+			identities: testIdentities(t, stranger),
+			want:       want{envs: nil, err: sops.ErrNoMatchingKey},
+		},
+		{
+			name:       "invalid identities",
+			filename:   "testdata/secrets.enc.json",
+			identities: testIdentities(t, "AGE-SECRET-KEY-broken"),
+			want:       want{envs: nil, err: sops.ErrInvalidIdentities},
+		},
+		{
+			name:       "missing identities file",
+			filename:   "testdata/secrets.enc.json",
+			identities: "testdata/not-exist.txt",
+			want:       want{envs: nil, err: sops.ErrInvalidIdentities},
+		},
+		{
+			name:       "missing identities",
+			filename:   "testdata/secrets.enc.json",
+			identities: "",
+			want:       want{envs: nil, err: sops.ErrMissingIdentities},
+		},
+		{
+			name:       "missing file",
+			filename:   "testdata/not-exist.env",
+			identities: keys,
+			want:       want{envs: nil, err: sops.ErrMissingFile},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(sops.NewWithConfig(sops.Config{
+				Filename:       test.filename,
+				IdentitiesFile: test.identities,
+				Separator:      test.separator,
+			}))
+
+			envs, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.envs, envs)
+		})
+	}
+}
+
+func testIdentities(t *testing.T, content string) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "keys.txt")
+
+	ex.MustDo(os.WriteFile(name, []byte(content), 0o600))
+
+	return name
+}
+
+func TestSourceExtractNotEncrypted(t *testing.T) {
+	t.Parallel()
+
+	plain := map[string]string{
+		"plain.env":  "KEY=value\n",
+		"plain.yaml": "KEY: value\n",
+		"plain.json": `{"KEY": "value"}`,
+	}
+
+	for name, content := range plain {
+		filename := filepath.Join(t.TempDir(), name)
+
+		ex.MustDo(os.WriteFile(filename, []byte(content), 0o600))
+
+		obj := ex.Must(sops.NewWithConfig(sops.Config{Filename: filename, IdentitiesFile: keys}))
+
+		envs, err := obj.Extract(t.Context())
+
+		require.ErrorIs(t, err, sops.ErrNotEncrypted, name)
+		assert.Nil(t, envs)
+	}
+}
+
+func TestSourceExtractIdentitiesEnv(t *testing.T) {
+	t.Setenv("TEST_SOPS_AGE_KEY", string(ex.Must(os.ReadFile(keys))))
+
+	obj := ex.Must(sops.NewWithConfig(sops.Config{
+		Filename:      "testdata/secrets.enc.json",
+		IdentitiesEnv: "TEST_SOPS_AGE_KEY",
+	}))
+
+	envs, err := obj.Extract(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", envs["token"])
+}
+
+func TestSourceSensitive(t *testing.T) {
+	t.Parallel()
+
+	obj := ex.Must(sops.NewWithConfig(sops.Config{Filename: "testdata/secrets.enc.env", IdentitiesFile: keys}))
+
+	assert.False(t, obj.Sensitive(&enw.Env{Var: "DB_PASSWORD"}))
+
+	_ = ex.Must(obj.Extract(t.Context()))
+
+	assert.True(t, obj.Sensitive(&enw.Env{Var: "DB_PASSWORD"}))
+	assert.True(t, obj.Sensitive(&enw.Env{Var: "EMPTY"}))
+	assert.False(t, obj.Sensitive(&enw.Env{Var: "sops_mac"}))
+	assert.False(t, obj.Sensitive(&enw.Env{Var: "HOME"}))
+}
@@ -0,0 +1,511 @@
+package sops
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/therenotomorrow/ex"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	metadataKey    = "sops"
+	metadataPrefix = metadataKey + "_"
+	ageSuffix      = "__map_enc"
+	ageList        = "age__list_"
+)
+
+var (
+	encryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+	// macOnlyEncrypted starts the MAC when only the encrypted values are
+	// authenticated, the same bytes as `sops` uses.
+	macOnlyEncrypted = []byte{
+		0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0x0b,
+		0x0b, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69,
+	}
+)
+
+type (
+	// item keeps the order of the document, the MAC depends on it.
+	item struct {
+		value any
+		key   string
+	}
+
+	branch []item
+
+	ageKey struct {
+		Enc string `json:"enc" yaml:"enc"`
+	}
+
+	keyGroup struct {
+		Age []ageKey `json:"age" yaml:"age"`
+	}
+
+	//nolint:tagliatelle // sops format
+	metadata struct {
+		LastModified     string     `json:"lastmodified"       yaml:"lastmodified"`
+		MAC              string     `json:"mac"                yaml:"mac"`
+		Age              []ageKey   `json:"age"                yaml:"age"`
+		KeyGroups        []keyGroup `json:"key_groups"         yaml:"key_groups"`
+		ShamirThreshold  int        `json:"shamir_threshold"   yaml:"shamir_threshold"`
+		MACOnlyEncrypted bool       `json:"mac_only_encrypted" yaml:"mac_only_encrypted"`
+	}
+
+	document struct {
+		meta     *metadata
+		branches []branch
+	}
+
+	decryptor struct {
+		hash hash.Hash
+		key  []byte
+		only bool
+	}
+)
+
+func (m *metadata) ages() []string {
+	encrypted := make([]string, 0)
+
+	for _, key := range m.Age {
+		encrypted = append(encrypted, key.Enc)
+	}
+
+	for _, group := range m.KeyGroups {
+		for _, key := range group.Age {
+			encrypted = append(encrypted, key.Enc)
+		}
+	}
+
+	return encrypted
+}
+
+func parse(data []byte, format Format) (*document, error) {
+	var (
+		doc *document
+		err error
+	)
+
+	switch format {
+	case Dotenv:
+		doc, err = parseDotenv(data)
+	case YAML:
+		doc, err = parseYAML(data)
+	case JSON:
+		doc, err = parseJSON(data)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.meta == nil || doc.meta.MAC == "" {
+		return nil, ErrNotEncrypted
+	}
+
+	return doc, nil
+}
+
+// parseDotenv reads the file as `sops` does: no quotes, `\n` is a newline
+// and the metadata is flattened into the `sops_` variables.
+func parseDotenv(data []byte) (*document, error) {
+	var (
+		tree  = make(branch, 0)
+		meta  = new(metadata)
+		found = false
+		ages  = make(map[string]string)
+	)
+
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, ErrInvalidFormat.Reason(line)
+		}
+
+		value = strings.ReplaceAll(value, `\n`, "\n")
+
+		name, isMeta := strings.CutPrefix(key, metadataPrefix)
+		if !isMeta {
+			tree = append(tree, item{key: key, value: value})
+
+			continue
+		}
+
+		found = true
+
+		switch {
+		case name == "lastmodified":
+			meta.LastModified = value
+		case name == "mac":
+			meta.MAC = value
+		case name == "mac_only_encrypted":
+			meta.MACOnlyEncrypted = value == "true"
+		case name == "shamir_threshold":
+			meta.ShamirThreshold, _ = strconv.Atoi(value)
+		case strings.Contains(name, ageList) && strings.HasSuffix(name, ageSuffix):
+			ages[name] = value
+		}
+	}
+
+	if !found {
+		return &document{meta: nil, branches: nil}, nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(ages)) {
+		meta.Age = append(meta.Age, ageKey{Enc: ages[name]})
+	}
+
+	return &document{meta: meta, branches: []branch{tree}}, nil
+}
+
+func parseYAML(data []byte) (*document, error) {
+	doc := &document{meta: nil, branches: make([]branch, 0)}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		var node yaml.Node
+
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, ErrInvalidFormat.Because(err)
+		}
+
+		if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+
+		tree, err := yamlBranch(node.Content[0], doc)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.branches = append(doc.branches, tree)
+	}
+
+	return doc, nil
+}
+
+func yamlBranch(node *yaml.Node, doc *document) (branch, error) {
+	tree := make(branch, 0, len(node.Content)/2) //nolint:mnd // keys and values
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		if doc != nil && key == metadataKey {
+			doc.meta = new(metadata)
+
+			err := value.Decode(doc.meta)
+			if err != nil {
+				return nil, ErrInvalidFormat.Because(err)
+			}
+
+			continue
+		}
+
+		val, err := yamlValue(value)
+		if err != nil {
+			return nil, err
+		}
+
+		tree = append(tree, item{key: key, value: val})
+	}
+
+	return tree, nil
+}
+
+func yamlValue(node *yaml.Node) (any, error) {
+	switch node.Kind { //nolint:exhaustive // documents are unwrapped before
+	case yaml.MappingNode:
+		return yamlBranch(node, nil)
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(node.Content))
+
+		for _, elem := range node.Content {
+			val, err := yamlValue(elem)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, val)
+		}
+
+		return list, nil
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	default:
+		var val any
+
+		err := node.Decode(&val)
+		if err != nil {
+			return nil, ErrInvalidFormat.Because(err)
+		}
+
+		return val, nil
+	}
+}
+
+// parseJSON reads the tokens instead of a map, because the keys order
+// is a part of the MAC.
+func parseJSON(data []byte) (*document, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('{') {
+		return nil, ErrInvalidFormat.Reason("json document must be an object")
+	}
+
+	doc := &document{meta: nil, branches: nil}
+
+	tree, err := jsonBranch(decoder, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.branches = []branch{tree}
+
+	return doc, nil
+}
+
+func jsonBranch(decoder *json.Decoder, doc *document) (branch, error) {
+	tree := make(branch, 0)
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, ErrInvalidFormat.Because(err)
+		}
+
+		key, _ := token.(string)
+
+		if doc != nil && key == metadataKey {
+			doc.meta = new(metadata)
+
+			err = decoder.Decode(doc.meta)
+			if err != nil {
+				return nil, ErrInvalidFormat.Because(err)
+			}
+
+			continue
+		}
+
+		val, err := jsonValue(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		tree = append(tree, item{key: key, value: val})
+	}
+
+	// the closing delimiter
+	_, err := decoder.Token()
+	if err != nil {
+		return nil, ErrInvalidFormat.Because(err)
+	}
+
+	return tree, nil
+}
+
+func jsonValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, ErrInvalidFormat.Because(err)
+	}
+
+	switch token {
+	case json.Delim('{'):
+		return jsonBranch(decoder, nil)
+	case json.Delim('['):
+		list := make([]any, 0)
+
+		for decoder.More() {
+			val, err := jsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, val)
+		}
+
+		_, err = decoder.Token()
+		if err != nil {
+			return nil, ErrInvalidFormat.Because(err)
+		}
+
+		return list, nil
+	default:
+		return token, nil
+	}
+}
+
+// decrypt walks the branches in the same order as `sops` does and compares
+// the MAC of the plaintext with the encrypted one from the metadata.
+func (d *document) decrypt(key []byte) ([]map[string]any, error) {
+	dec := &decryptor{hash: sha512.New(), key: key, only: d.meta.MACOnlyEncrypted}
+	if dec.only {
+		dec.hash.Write(macOnlyEncrypted)
+	}
+
+	trees := make([]map[string]any, 0, len(d.branches))
+
+	for _, tree := range d.branches {
+		val, err := dec.walk(tree, make([]string, 0))
+		if err != nil {
+			return nil, err
+		}
+
+		plain, _ := val.(map[string]any)
+		trees = append(trees, plain)
+	}
+
+	mac, err := decryptValue(d.meta.MAC, key, d.meta.LastModified)
+	if err != nil {
+		return nil, ErrMACMismatch.Because(err)
+	}
+
+	if string(mac) != fmt.Sprintf("%X", dec.hash.Sum(nil)) {
+		return nil, ErrMACMismatch
+	}
+
+	return trees, nil
+}
+
+func (d *decryptor) walk(value any, path []string) (any, error) {
+	switch typed := value.(type) {
+	case branch:
+		plain := make(map[string]any, len(typed))
+
+		for _, elem := range typed {
+			val, err := d.walk(elem.value, append(path, elem.key))
+			if err != nil {
+				return nil, err
+			}
+
+			plain[elem.key] = val
+		}
+
+		return plain, nil
+	case []any:
+		// the list items share the path of the list
+		plain := make([]any, 0, len(typed))
+
+		for _, elem := range typed {
+			val, err := d.walk(elem, path)
+			if err != nil {
+				return nil, err
+			}
+
+			plain = append(plain, val)
+		}
+
+		return plain, nil
+	case nil:
+		return nil, nil
+	case string:
+		return d.leaf(typed, path)
+	default:
+		if !d.only {
+			d.hash.Write(toBytes(typed))
+		}
+
+		return typed, nil
+	}
+}
+
+func (d *decryptor) leaf(value string, path []string) (any, error) {
+	matches := encryptedValue.FindStringSubmatch(value)
+	if matches == nil {
+		if !d.only {
+			d.hash.Write([]byte(value))
+		}
+
+		return value, nil
+	}
+
+	plain, err := decryptValue(value, d.key, strings.Join(path, ":")+":")
+	if err != nil {
+		return nil, ErrDecryptValue.Because(fmt.Errorf("%s: %w", strings.Join(path, ":"), err))
+	}
+
+	d.hash.Write(plain)
+
+	if matches[4] == "bool" {
+		return strconv.ParseBool(string(plain))
+	}
+
+	return string(plain), nil
+}
+
+func decryptValue(value string, key []byte, additional string) ([]byte, error) {
+	matches := encryptedValue.FindStringSubmatch(value)
+	if matches == nil {
+		return nil, ErrDecryptValue.Reason("not a sops value")
+	}
+
+	parts := make([][]byte, 0, len(matches)-2) //nolint:mnd // data, iv and tag
+
+	for _, part := range matches[1:4] {
+		decoded, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return nil, ex.Unexpected(err)
+		}
+
+		parts = append(parts, decoded)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ex.Unexpected(err)
+	}
+
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(parts[1]))
+	if err != nil {
+		return nil, ex.Unexpected(err)
+	}
+
+	return gcm.Open(nil, parts[1], slices.Concat(parts[0], parts[2]), []byte(additional))
+}
+
+// toBytes formats the unencrypted values for the MAC as `sops` does.
+func toBytes(value any) []byte {
+	switch typed := value.(type) {
+	case int:
+		return []byte(strconv.Itoa(typed))
+	case float64:
+		return []byte(strconv.FormatFloat(typed, 'f', -1, 64))
+	case bool:
+		if typed {
+			return []byte("True")
+		}
+
+		return []byte("False")
+	case time.Time:
+		text, _ := typed.MarshalText()
+
+		return text
+	default:
+		return fmt.Append(nil, typed)
+	}
+}
@@ -0,0 +1,265 @@
+package systemd
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	stateKey = iota
+	stateComment
+	stateName
+	statePreValue
+	stateValue
+	stateValueEscape
+	stateSingleQuote
+	stateDoubleQuote
+	stateDoubleQuoteEscape
+)
+
+// Split parses the value of `Environment=`: the assignments are separated
+// by whitespace, the quotes group them and the C-style escapes are applied.
+// The assignments without `=` or with an invalid name are skipped.
+func Split(value string) (map[string]string, error) {
+	envs := make(map[string]string)
+
+	for {
+		value = strings.TrimLeft(value, " \t\n\r")
+		if value == "" {
+			return envs, nil
+		}
+
+		word, rest, err := firstWord(value)
+		if err != nil {
+			return nil, err
+		}
+
+		key, val, ok := strings.Cut(word, "=")
+		if ok && validName(key) {
+			envs[key] = val
+		}
+
+		value = rest
+	}
+}
+
+func firstWord(value string) (string, string, error) {
+	var (
+		word  strings.Builder
+		quote byte
+	)
+
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+
+		switch {
+		case char == '\\':
+			unescaped, size, err := unescape(value[i+1:])
+			if err != nil {
+				return "", "", err
+			}
+
+			word.WriteString(unescaped)
+
+			i += size
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteByte(char)
+		case char == '\'' || char == '"':
+			quote = char
+		case strings.IndexByte(" \t\n\r", char) >= 0:
+			return word.String(), value[i:], nil
+		default:
+			word.WriteByte(char)
+		}
+	}
+
+	if quote != 0 {
+		return "", "", ErrInvalidUnit.Reason("unterminated quote in " + value)
+	}
+
+	return word.String(), "", nil
+}
+
+// unescape decodes the escape after the backslash and returns its size.
+func unescape(text string) (string, int, error) {
+	if text == "" {
+		return "", 0, ErrInvalidUnit.Reason("trailing backslash")
+	}
+
+	simple := map[byte]string{
+		'a': "\a", 'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+		'\\': "\\", '"': "\"", '\'': "'", 's': " ",
+	}
+
+	if char, ok := simple[text[0]]; ok {
+		return char, 1, nil
+	}
+
+	var (
+		digits string
+		base   int
+	)
+
+	switch {
+	case text[0] == 'x' && len(text) >= 3:
+		digits, base = text[1:3], 16
+	case text[0] == 'u' && len(text) >= 5:
+		digits, base = text[1:5], 16
+	case text[0] == 'U' && len(text) >= 9:
+		digits, base = text[1:9], 16
+	case text[0] >= '0' && text[0] <= '7' && len(text) >= 3:
+		digits, base = text[:3], 8
+	default:
+		return "", 0, ErrInvalidUnit.Reason(`invalid escape \` + text[:1])
+	}
+
+	code, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || code == 0 {
+		return "", 0, ErrInvalidUnit.Reason(`invalid escape \` + text[:len(digits)])
+	}
+
+	size := len(digits)
+	if base == 16 {
+		size++
+	}
+
+	if text[0] == 'x' || base == 8 {
+		if code > 0xff {
+			return "", 0, ErrInvalidUnit.Reason(`invalid escape \` + text[:len(digits)])
+		}
+
+		return string([]byte{byte(code)}), size, nil
+	}
+
+	return string(rune(code)), size, nil
+}
+
+// Parse reads the `EnvironmentFile=` format: the shell-like assignments one
+// per line, the single and double quotes, the backslash escapes and the line
+// continuations. The comments and the lines without `=` are skipped.
+func Parse(data []byte) map[string]string {
+	var (
+		envs  = make(map[string]string)
+		state = stateKey
+		key   []rune
+		value []rune
+		// kept is the size of the value without the unquoted trailing spaces
+		kept int
+	)
+
+	write := func(char rune) {
+		value = append(value, char)
+		kept = len(value)
+	}
+
+	push := func() {
+		name := strings.TrimRight(string(key), " \t")
+		if validName(name) {
+			envs[name] = string(value[:kept])
+		}
+
+		state, key, value, kept = stateKey, nil, nil, 0
+	}
+
+	for _, char := range string(data) {
+		newline := char == '\n' || char == '\r'
+		space := char == ' ' || char == '\t'
+
+		switch state {
+		case stateKey:
+			switch {
+			case char == '#' || char == ';':
+				state = stateComment
+			case !newline && !space:
+				state = stateName
+				key = append(key, char)
+			}
+		case stateComment:
+			if newline {
+				state = stateKey
+			}
+		case stateName:
+			switch {
+			case newline:
+				state, key = stateKey, nil
+			case char == '=':
+				state = statePreValue
+			default:
+				key = append(key, char)
+			}
+		case statePreValue, stateValue:
+			switch {
+			case newline:
+				push()
+			case char == '\'' && state == statePreValue:
+				state = stateSingleQuote
+			case char == '"' && state == statePreValue:
+				state = stateDoubleQuote
+			case char == '\\':
+				state = stateValueEscape
+			case space && state == statePreValue:
+			case space:
+				value = append(value, char)
+			default:
+				state = stateValue
+
+				write(char)
+			}
+		case stateValueEscape:
+			state = stateValue
+
+			if !newline {
+				write(char)
+			}
+		case stateSingleQuote:
+			if char == '\'' {
+				state = statePreValue
+			} else {
+				write(char)
+			}
+		case stateDoubleQuote:
+			switch char {
+			case '"':
+				state = statePreValue
+			case '\\':
+				state = stateDoubleQuoteEscape
+			default:
+				write(char)
+			}
+		case stateDoubleQuoteEscape:
+			state = stateDoubleQuote
+
+			switch {
+			case strings.ContainsRune("\"\\`$", char):
+				write(char)
+			case char != '\n':
+				write('\\')
+				write(char)
+			}
+		}
+	}
+
+	if state != stateKey && state != stateComment && state != stateName {
+		push()
+	}
+
+	return envs
+}
+
+// validName accepts the names that the shell can use.
+func validName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+
+	for _, char := range name {
+		letter := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+		if !letter && (char < '0' || char > '9') {
+			return false
+		}
+	}
+
+	return true
+}
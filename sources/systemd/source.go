@@ -0,0 +1,303 @@
+// Package systemd reproduces the environment that systemd gives to a unit:
+// the `Environment=` assignments of the unit and its `.d/*.conf` drop-ins,
+// then the `EnvironmentFile=` files on top, as described in
+// systemd.exec(5).
+//
+// Only the `%%`, `%n`, `%N`, `%p` and `%i` specifiers are resolved, the
+// other ones are kept as they are written.
+package systemd
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultSection = "Service"
+	defaultRoot    = "/"
+
+	keyEnvironment     = "Environment"
+	keyEnvironmentFile = "EnvironmentFile"
+
+	ErrMissingFilename ex.Const = "missing filename"
+	ErrMissingFile     ex.Const = "missing file"
+	ErrInvalidUnit     ex.Const = "invalid unit file"
+)
+
+type (
+	// Config points to the unit file, the drop-ins are read from the
+	// `<unit>.d` directories next to it. Root is prepended to the paths of
+	// `EnvironmentFile=`, so the units of a mounted image can be checked.
+	Config struct {
+		Filename string
+		Section  string
+		Root     string
+	}
+
+	Source struct {
+		config Config
+	}
+
+	// assignments keeps the settings in the order they are written, the
+	// empty assignment resets the list as in systemd.
+	assignments struct {
+		environment []string
+		files       []string
+	}
+)
+
+func (c *Config) Validate() error {
+	if c.Filename == "" {
+		return ErrMissingFilename
+	}
+
+	return nil
+}
+
+func New(filename string) (*Source, error) {
+	return NewWithConfig(Config{Filename: filename, Section: defaultSection, Root: defaultRoot})
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Section = cmp.Or(config.Section, defaultSection)
+	config.Root = cmp.Or(config.Root, defaultRoot)
+
+	return &Source{config: config}, nil
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+func (s *Source) Extract(_ context.Context) (map[string]string, error) {
+	var assigned assignments
+
+	files := append([]string{s.config.Filename}, s.dropIns()...)
+
+	for _, file := range files {
+		err := s.parseUnit(file, &assigned)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	envs := make(map[string]string)
+
+	for _, value := range assigned.environment {
+		vars, err := Split(s.specifiers(value))
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(envs, vars)
+	}
+
+	// the files override the assignments and are read in the order
+	for _, value := range assigned.files {
+		vars, err := s.environmentFile(s.specifiers(value))
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(envs, vars)
+	}
+
+	return envs, nil
+}
+
+// dropIns lists the `.conf` files of the template and the unit drop-in
+// directories sorted by name, the unit one wins for the same name.
+func (s *Source) dropIns() []string {
+	dir, name := filepath.Split(s.config.Filename)
+
+	dirs := make([]string, 0)
+	if prefix, _, ok := strings.Cut(name, "@"); ok {
+		dirs = append(dirs, prefix+"@"+filepath.Ext(name)+".d")
+	}
+
+	dirs = append(dirs, name+".d")
+	confs := make(map[string]string)
+
+	for _, sub := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, sub, "*.conf"))
+		for _, match := range matches {
+			confs[filepath.Base(match)] = match
+		}
+	}
+
+	files := make([]string, 0, len(confs))
+	for _, name := range slices.Sorted(maps.Keys(confs)) {
+		files = append(files, confs[name])
+	}
+
+	return files
+}
+
+// parseUnit collects the settings of the section, the lines ending with the
+// backslash are joined and the comment lines between them are skipped.
+func (s *Source) parseUnit(filename string, assigned *assignments) error {
+	data, err := read(filename)
+	if err != nil {
+		return err
+	}
+
+	var (
+		section string
+		line    string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if cut, ok := strings.CutSuffix(text, `\`); ok {
+			line += cut + " "
+
+			continue
+		}
+
+		line, text = "", strings.TrimSpace(line+text)
+
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "["):
+			if !strings.HasSuffix(text, "]") {
+				return ErrInvalidUnit.Reason(filename + ": " + text)
+			}
+
+			section = text[1 : len(text)-1]
+		case section == s.config.Section:
+			key, value, ok := strings.Cut(text, "=")
+			if !ok {
+				return ErrInvalidUnit.Reason(filename + ": " + text)
+			}
+
+			assigned.add(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return ex.Unexpected(err)
+	}
+
+	return nil
+}
+
+func (a *assignments) add(key string, value string) {
+	var list *[]string
+
+	switch key {
+	case keyEnvironment:
+		list = &a.environment
+	case keyEnvironmentFile:
+		list = &a.files
+	default:
+		return
+	}
+
+	if value == "" {
+		*list = nil
+	} else {
+		*list = append(*list, value)
+	}
+}
+
+// environmentFile reads the file or the files matching the pattern, the
+// missing ones are skipped when the path starts with `-`.
+func (s *Source) environmentFile(value string) (map[string]string, error) {
+	pattern, optional := strings.CutPrefix(value, "-")
+	pattern = filepath.Join(s.config.Root, pattern)
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, ErrInvalidUnit.Because(err)
+	}
+
+	if len(matches) == 0 && !optional {
+		return nil, ErrMissingFile.Reason(pattern)
+	}
+
+	envs := make(map[string]string)
+
+	for _, match := range matches {
+		data, err := read(match)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(envs, Parse(data))
+	}
+
+	return envs, nil
+}
+
+// specifiers resolves the specifiers derived from the unit name.
+func (s *Source) specifiers(value string) string {
+	name := filepath.Base(s.config.Filename)
+	unit := strings.TrimSuffix(name, filepath.Ext(name))
+	prefix, instance, _ := strings.Cut(unit, "@")
+
+	var out strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 == len(value) {
+			out.WriteByte(value[i])
+
+			continue
+		}
+
+		i++
+
+		switch value[i] {
+		case '%':
+			out.WriteByte('%')
+		case 'n':
+			out.WriteString(name)
+		case 'N':
+			out.WriteString(unit)
+		case 'p':
+			out.WriteString(prefix)
+		case 'i':
+			out.WriteString(instance)
+		default:
+			out.WriteString(value[i-1 : i+1])
+		}
+	}
+
+	return out.String()
+}
+
+func read(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrMissingFile.Reason(filename)
+	}
+
+	if err != nil {
+		return nil, ex.Unexpected(err)
+	}
+
+	return data, nil
+}
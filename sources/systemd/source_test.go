@@ -0,0 +1,277 @@
+package systemd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/systemd"
+	"github.com/therenotomorrow/ex"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err    error
+		config systemd.Config
+		name   string
+	}{
+		{name: "valid", config: systemd.Config{Filename: "billing.service"}, err: nil},
+		{name: "missing filename", config: systemd.Config{Section: "Service"}, err: systemd.ErrMissingFilename},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, test.config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	obj, err := systemd.New("billing.service")
+
+	require.NoError(t, err)
+	assert.Equal(t, systemd.Config{Filename: "billing.service", Section: "Service", Root: "/"}, obj.Config())
+
+	obj, err = systemd.NewWithConfig(systemd.Config{Filename: "db.mount"})
+
+	require.NoError(t, err)
+	assert.Equal(t, systemd.Config{Filename: "db.mount", Section: "Service", Root: "/"}, obj.Config())
+
+	obj, err = systemd.New("")
+
+	require.ErrorIs(t, err, systemd.ErrMissingFilename)
+	assert.Nil(t, obj)
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		want  map[string]string
+		err   error
+		name  string
+		value string
+	}{
+		{name: "plain", value: "A=1  B=2", want: map[string]string{"A": "1", "B": "2"}, err: nil},
+		{
+			name:  "quotes",
+			value: `"A=a b" 'B=say "hi"' C="x"y`,
+			want:  map[string]string{"A": "a b", "B": `say "hi"`, "C": "xy"},
+			err:   nil,
+		},
+		{
+			name:  "escapes",
+			value: `A=a\tb\x21\101 B="\"q\"\s" C=\u00e9`,
+			want:  map[string]string{"A": "a\tb!A", "B": `"q" `, "C": "é"},
+			err:   nil,
+		},
+		{name: "later wins", value: "A=1 A=2 B=", want: map[string]string{"A": "2", "B": ""}, err: nil},
+		{name: "skipped", value: "NOVALUE 1A=x A-B=x =x", want: map[string]string{}, err: nil},
+		{name: "unterminated quote", value: `A="x`, want: nil, err: systemd.ErrInvalidUnit},
+		{name: "invalid escape", value: `A=\q`, want: nil, err: systemd.ErrInvalidUnit},
+		{name: "nul escape", value: `A=\x00`, want: nil, err: systemd.ErrInvalidUnit},
+		{name: "trailing backslash", value: `A=x\`, want: nil, err: systemd.ErrInvalidUnit},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := systemd.Split(test.value)
+
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	data := "# comment\n; comment\n  PORT=6432\nSPACED = value with spaces   \nESCAPED=a\\ \n" +
+		"SINGLE='a \"b\" $c'\nDOUBLE=\"q\\\" \\$HOME \\n\"\nJOINED=\"x\" 'y'\nCONTINUED=first\\\nsecond\n" +
+		"NOVALUE\n1ST=x\r\nEMPTY=\nLAST=eof"
+
+	want := map[string]string{
+		"PORT":      "6432",
+		"SPACED":    "value with spaces",
+		"ESCAPED":   "a ",
+		"SINGLE":    `a "b" $c`,
+		"DOUBLE":    `q" $HOME \n`,
+		"JOINED":    "xy",
+		"CONTINUED": "firstsecond",
+		"EMPTY":     "",
+		"LAST":      "eof",
+	}
+
+	assert.Equal(t, want, systemd.Parse([]byte(data)))
+}
+
+// testUnits writes the files relative to the temporary directory.
+func testUnits(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+
+		ex.MustDo(os.MkdirAll(filepath.Dir(path), 0o700))
+		ex.MustDo(os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	return root
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	root := testUnits(t, map[string]string{
+		"etc/billing/common.env":   "DB_PORT=6432\nFROM_FILE=common\n",
+		"etc/billing/conf.d/a.env": "FROM_FILE=a\nGLOB=a\n",
+		"etc/billing/conf.d/b.env": "GLOB=b\n",
+		"etc/billing/billing.env":  "INSTANCE=billing\n",
+		"etc/worker/eu.env":        "REGION=eu-west-1\n",
+		"units/billing.service":    billingUnit,
+		"units/billing.service.d/10-db.conf": "[Service]\nEnvironment=DB_HOST=db.internal\n" +
+			"EnvironmentFile=-/etc/billing/%p.env\n",
+		"units/billing.service.d/README":      "[Service]\nEnvironment=IGNORED=not-conf\n",
+		"units/reset.service":                 "[Service]\nEnvironment=A=1 B=2\nEnvironmentFile=/etc/not-exist.env\n",
+		"units/reset.service.d/override.conf": "[Service]\nEnvironment=\nEnvironment=B=3\nEnvironmentFile=\n",
+		"units/worker@.service":               "[Service]\nEnvironment=NAME=%n UNIT=%N PREFIX=%p INSTANCE=%i\n",
+		"units/worker@.service.d/10.conf":     "[Service]\nEnvironment=QUEUE=default TEMPLATE=yes\n",
+		"units/worker@eu.service.d/10.conf": "[Service]\nEnvironment=QUEUE=eu\n" +
+			"EnvironmentFile=/etc/worker/%i.env\n",
+		"units/worker@eu.service":      "[Service]\nEnvironment=NAME=%n UNIT=%N PREFIX=%p INSTANCE=%i\n",
+		"units/db.mount":               "[Service]\nEnvironment=A=service\n[Mount]\nEnvironment=A=mount\n",
+		"units/missing.service":        "[Service]\nEnvironmentFile=/etc/not-exist.env\n",
+		"units/broken-quote.service":   "[Service]\nEnvironment=\"A=1\n",
+		"units/broken-section.service": "[Service\nEnvironment=A=1\n",
+		"units/broken-line.service":    "[Service]\nEnvironment\n",
+	})
+
+	type want struct {
+		envs map[string]string
+		err  error
+	}
+
+	tests := []struct {
+		want   want
+		name   string
+		config systemd.Config
+	}{
+		{
+			name:   "unit with drop-ins and files",
+			config: systemd.Config{Filename: filepath.Join(root, "units/billing.service"), Root: root},
+			want: want{
+				envs: map[string]string{
+					"DB_HOST":   "db.internal",
+					"DB_PORT":   "6432",
+					"GREETING":  "hello world",
+					"QUOTE":     `it said "hi"`,
+					"TAB":       "a\tb",
+					"PERCENT":   "100%",
+					"LONG":      "one",
+					"TWO":       "two",
+					"FROM_FILE": "a",
+					"GLOB":      "b",
+					"INSTANCE":  "billing",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:   "empty assignment resets",
+			config: systemd.Config{Filename: filepath.Join(root, "units/reset.service"), Root: root},
+			want:   want{envs: map[string]string{"B": "3"}, err: nil},
+		},
+		{
+			name:   "template instance",
+			config: systemd.Config{Filename: filepath.Join(root, "units/worker@eu.service"), Root: root},
+			want: want{
+				envs: map[string]string{
+					"NAME":     "worker@eu.service",
+					"UNIT":     "worker@eu",
+					"PREFIX":   "worker",
+					"INSTANCE": "eu",
+					"QUEUE":    "eu",
+					"REGION":   "eu-west-1",
+				},
+				err: nil,
+			},
+		},
+		{
+			name:   "other section",
+			config: systemd.Config{Filename: filepath.Join(root, "units/db.mount"), Section: "Mount"},
+			want:   want{envs: map[string]string{"A": "mount"}, err: nil},
+		},
+		{
+			name:   "missing environment file",
+			config: systemd.Config{Filename: filepath.Join(root, "units/missing.service"), Root: root},
+			want:   want{envs: nil, err: systemd.ErrMissingFile},
+		},
+		{
+			name:   "missing unit",
+			config: systemd.Config{Filename: filepath.Join(root, "units/not-exist.service")},
+			want:   want{envs: nil, err: systemd.ErrMissingFile},
+		},
+		{
+			name:   "unterminated quote",
+			config: systemd.Config{Filename: filepath.Join(root, "units/broken-quote.service")},
+			want:   want{envs: nil, err: systemd.ErrInvalidUnit},
+		},
+		{
+			name:   "unterminated section",
+			config: systemd.Config{Filename: filepath.Join(root, "units/broken-section.service")},
+			want:   want{envs: nil, err: systemd.ErrInvalidUnit},
+		},
+		{
+			name:   "line without assignment",
+			config: systemd.Config{Filename: filepath.Join(root, "units/broken-line.service")},
+			want:   want{envs: nil, err: systemd.ErrInvalidUnit},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			obj := ex.Must(systemd.NewWithConfig(test.config))
+
+			envs, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.want.err)
+			assert.Equal(t, test.want.envs, envs)
+		})
+	}
+}
+
+const billingUnit = `[Unit]
+Description=Billing
+Environment=IGNORED=unit-section
+
+[Service]
+# the comments are skipped
+ExecStart=/usr/bin/billing
+Environment=DB_HOST=localhost DB_PORT=5432
+Environment="GREETING=hello world" 'QUOTE=it said "hi"'
+Environment=TAB=a\tb PERCENT=100%%
+Environment=LONG=one \
+; the comments between the continued lines are skipped too
+  TWO=two
+Environment=INVALID-NAME=x NOVALUE 1ST=x
+EnvironmentFile=/etc/billing/common.env
+EnvironmentFile=-/etc/billing/not-exist.env
+EnvironmentFile=/etc/billing/conf.d/*.env
+
+[Install]
+WantedBy=multi-user.target
+`
@@ -0,0 +1,257 @@
+package vault
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/therenotomorrow/enw/internal/flatten"
+	"github.com/therenotomorrow/ex"
+)
+
+const (
+	defaultMount          = "secret"
+	defaultSeparator      = "_"
+	defaultTimeout        = 10 * time.Second
+	defaultAppRoleMount   = "approle"
+	defaultKubernetesPath = "kubernetes"
+	defaultJWTFile        = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	headerToken     = "X-Vault-Token"
+	headerNamespace = "X-Vault-Namespace"
+
+	Token      Method = "token"
+	AppRole    Method = "approle"
+	Kubernetes Method = "kubernetes"
+
+	ErrMissingAddress     ex.Const = "missing address"
+	ErrMissingPaths       ex.Const = "missing paths"
+	ErrMissingMethod      ex.Const = "missing method"
+	ErrInvalidMethod      ex.Const = "invalid method"
+	ErrMissingToken       ex.Const = "missing token"
+	ErrMissingCredentials ex.Const = "missing credentials"
+	ErrInvalidVersion     ex.Const = "invalid version, must not be negative"
+	ErrSecretNotFound     ex.Const = "secret not found"
+	ErrVaultError         ex.Const = "vault error"
+)
+
+type (
+	Method string
+
+	Config struct {
+		Address   string
+		Namespace string
+		Mount     string
+		Method    Method
+		Token     string
+		RoleID    string
+		SecretID  string
+		Role      string
+		JWTFile   string
+		AuthMount string
+		Separator string
+		Paths     []string
+		Version   int
+		Timeout   time.Duration
+	}
+
+	Source struct {
+		client *http.Client
+		config Config
+	}
+
+	secret struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+
+	login struct {
+		Auth struct {
+			ClientToken string `json:"client_token"` //nolint:tagliatelle // vault api
+		} `json:"auth"`
+	}
+)
+
+func (c *Config) Validate() error {
+	switch {
+	case c.Address == "":
+		return ErrMissingAddress
+	case len(c.Paths) == 0:
+		return ErrMissingPaths
+	case c.Version < 0:
+		return ErrInvalidVersion
+	}
+
+	switch c.Method {
+	case "":
+		return ErrMissingMethod
+	case Token:
+		if c.Token == "" {
+			return ErrMissingToken
+		}
+	case AppRole:
+		if c.RoleID == "" || c.SecretID == "" {
+			return ErrMissingCredentials
+		}
+	case Kubernetes:
+		if c.Role == "" {
+			return ErrMissingCredentials
+		}
+	default:
+		return ErrInvalidMethod
+	}
+
+	return nil
+}
+
+func (c *Config) merge() {
+	c.Address = strings.TrimSuffix(c.Address, "/")
+	c.Mount = cmp.Or(strings.Trim(c.Mount, "/"), defaultMount)
+	c.Separator = cmp.Or(c.Separator, defaultSeparator)
+	c.Timeout = cmp.Or(c.Timeout, defaultTimeout)
+	c.JWTFile = cmp.Or(c.JWTFile, defaultJWTFile)
+
+	switch c.Method { //nolint:exhaustive // only logins have their mounts
+	case AppRole:
+		c.AuthMount = cmp.Or(c.AuthMount, defaultAppRoleMount)
+	case Kubernetes:
+		c.AuthMount = cmp.Or(c.AuthMount, defaultKubernetesPath)
+	}
+}
+
+func NewWithConfig(config Config) (*Source, error) {
+	err := config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	config.merge()
+
+	return &Source{client: &http.Client{Timeout: config.Timeout}, config: config}, nil //nolint:exhaustruct // defaults
+}
+
+func (s *Source) Config() Config {
+	return s.config
+}
+
+// Extract reads the paths in order, so the later paths override the keys of
+// the earlier ones. Nested fields are joined with the separator.
+func (s *Source) Extract(ctx context.Context) (map[string]string, error) {
+	token, err := s.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	envs := make(map[string]string)
+
+	for _, path := range s.config.Paths {
+		query := url.Values{}
+		if s.config.Version != 0 {
+			query.Set("version", strconv.Itoa(s.config.Version))
+		}
+
+		endpoint := "/v1/" + s.config.Mount + "/data/" + strings.Trim(path, "/")
+		if len(query) != 0 {
+			endpoint += "?" + query.Encode()
+		}
+
+		var data secret
+
+		err = s.do(ctx, http.MethodGet, endpoint, token, nil, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(envs, flatten.Map(data.Data.Data, s.config.Separator))
+	}
+
+	return envs, nil
+}
+
+func (s *Source) login(ctx context.Context) (string, error) {
+	var (
+		payload map[string]string
+		auth    login
+	)
+
+	switch s.config.Method { //nolint:exhaustive // token has no login
+	case AppRole:
+		payload = map[string]string{"role_id": s.config.RoleID, "secret_id": s.config.SecretID}
+	case Kubernetes:
+		jwt, err := os.ReadFile(s.config.JWTFile)
+		if err != nil {
+			return "", ErrMissingCredentials.Because(err)
+		}
+
+		payload = map[string]string{"role": s.config.Role, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		return s.config.Token, nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", ex.Unexpected(err)
+	}
+
+	err = s.do(ctx, http.MethodPost, "/v1/auth/"+s.config.AuthMount+"/login", "", bytes.NewReader(body), &auth)
+	if err != nil {
+		return "", err
+	}
+
+	if auth.Auth.ClientToken == "" {
+		return "", ErrVaultError.Reason("empty client token")
+	}
+
+	return auth.Auth.ClientToken, nil
+}
+
+func (s *Source) do(ctx context.Context, method string, endpoint string, token string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.config.Address+endpoint, body)
+	if err != nil {
+		return ErrVaultError.Because(err)
+	}
+
+	if token != "" {
+		req.Header.Set(headerToken, token)
+	}
+
+	if s.config.Namespace != "" {
+		req.Header.Set(headerNamespace, s.config.Namespace)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return ErrVaultError.Because(err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrSecretNotFound.Reason(endpoint)
+	case resp.StatusCode >= http.StatusBadRequest:
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10)) //nolint:mnd // enough for vault errors
+
+		return ErrVaultError.Reason(resp.Status + ": " + strings.TrimSpace(string(text)))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	err = decoder.Decode(out)
+	if err != nil {
+		return ErrVaultError.Because(err)
+	}
+
+	return nil
+}
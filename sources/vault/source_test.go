@@ -0,0 +1,295 @@
+package vault_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/therenotomorrow/enw/sources/vault"
+)
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := func(method vault.Method) vault.Config {
+		return vault.Config{
+			Address: "http://vault", Paths: []string{"app"}, Method: method,
+			Token: "t", RoleID: "r", SecretID: "s", Role: "app",
+		}
+	}
+
+	tests := []struct {
+		err    error
+		config func() vault.Config
+		name   string
+	}{
+		{name: "token", config: func() vault.Config { return valid(vault.Token) }, err: nil},
+		{name: "approle", config: func() vault.Config { return valid(vault.AppRole) }, err: nil},
+		{name: "kubernetes", config: func() vault.Config { return valid(vault.Kubernetes) }, err: nil},
+		{name: "missing address", config: func() vault.Config {
+			config := valid(vault.Token)
+			config.Address = ""
+
+			return config
+		}, err: vault.ErrMissingAddress},
+		{name: "missing paths", config: func() vault.Config {
+			config := valid(vault.Token)
+			config.Paths = nil
+
+			return config
+		}, err: vault.ErrMissingPaths},
+		{name: "invalid version", config: func() vault.Config {
+			config := valid(vault.Token)
+			config.Version = -1
+
+			return config
+		}, err: vault.ErrInvalidVersion},
+		{name: "missing method", config: func() vault.Config { return valid("") }, err: vault.ErrMissingMethod},
+		{name: "invalid method", config: func() vault.Config { return valid("ldap") }, err: vault.ErrInvalidMethod},
+		{name: "missing token", config: func() vault.Config {
+			config := valid(vault.Token)
+			config.Token = ""
+
+			return config
+		}, err: vault.ErrMissingToken},
+		{name: "missing approle", config: func() vault.Config {
+			config := valid(vault.AppRole)
+			config.SecretID = ""
+
+			return config
+		}, err: vault.ErrMissingCredentials},
+		{name: "missing role", config: func() vault.Config {
+			config := valid(vault.Kubernetes)
+			config.Role = ""
+
+			return config
+		}, err: vault.ErrMissingCredentials},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config()
+
+			require.ErrorIs(t, config.Validate(), test.err)
+		})
+	}
+}
+
+func TestNewWithConfig(t *testing.T) {
+	t.Parallel()
+
+	obj, err := vault.NewWithConfig(vault.Config{
+		Address: "http://vault/", Paths: []string{"app"}, Method: vault.AppRole,
+	})
+
+	require.ErrorIs(t, err, vault.ErrMissingCredentials)
+	assert.Nil(t, obj)
+
+	obj, err = vault.NewWithConfig(vault.Config{
+		Address: "http://vault/", Paths: []string{"app"}, Method: vault.AppRole, RoleID: "r", SecretID: "s",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "http://vault", obj.Config().Address)
+	assert.Equal(t, "secret", obj.Config().Mount)
+	assert.Equal(t, "approle", obj.Config().AuthMount)
+	assert.Equal(t, "_", obj.Config().Separator)
+}
+
+// server speaks the subset of the Vault HTTP API the source uses.
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	secrets := map[string]string{
+		"/v1/secret/data/app":           `{"data": {"data": {"HOST": "db", "DB": {"PORT": 5432, "USER": "app"}}}}`,
+		"/v1/secret/data/app?version=2": `{"data": {"data": {"HOST": "old"}}}`,
+		"/v1/secret/data/override":      `{"data": {"data": {"HOST": "override"}}}`,
+		"/v1/kv/data/team/app":          `{"data": {"data": {"TOKEN": "t"}}}`,
+	}
+
+	logins := map[string]map[string]string{
+		"/v1/auth/approle/login":    {"role_id": "role", "secret_id": "secret"},
+		"/v1/auth/k8s/login":        {"role": "app", "jwt": "jwt-token"},
+		"/v1/auth/kubernetes/login": {"role": "app", "jwt": "jwt-token"},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want, ok := logins[r.URL.Path]; ok {
+			var got map[string]string
+
+			_ = json.NewDecoder(r.Body).Decode(&got)
+
+			if r.Method != http.MethodPost || !assert.ObjectsAreEqual(want, got) {
+				http.Error(w, `{"errors": ["permission denied"]}`, http.StatusForbidden)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"auth": {"client_token": "issued"}}`))
+
+			return
+		}
+
+		if token := r.Header.Get("X-Vault-Token"); token != "root" && token != "issued" {
+			http.Error(w, `{"errors": ["permission denied"]}`, http.StatusForbidden)
+
+			return
+		}
+
+		if r.Header.Get("X-Vault-Namespace") == "broken" {
+			_, _ = w.Write([]byte(`{`))
+
+			return
+		}
+
+		body, ok := secrets[r.URL.RequestURI()]
+		if !ok {
+			http.Error(w, `{"errors": []}`, http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	})
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestSourceExtract(t *testing.T) {
+	t.Parallel()
+
+	srv := server(t)
+	jwt := filepath.Join(t.TempDir(), "token")
+
+	require.NoError(t, os.WriteFile(jwt, []byte("jwt-token\n"), 0o600))
+
+	tests := []struct {
+		err    error
+		want   map[string]string
+		config vault.Config
+		name   string
+	}{
+		{
+			name:   "token",
+			config: vault.Config{Method: vault.Token, Token: "root", Paths: []string{"app"}},
+			want:   map[string]string{"HOST": "db", "DB_PORT": "5432", "DB_USER": "app"},
+			err:    nil,
+		},
+		{
+			name:   "paths override",
+			config: vault.Config{Method: vault.Token, Token: "root", Paths: []string{"app", "/override/"}},
+			want:   map[string]string{"HOST": "override", "DB_PORT": "5432", "DB_USER": "app"},
+			err:    nil,
+		},
+		{
+			name:   "version",
+			config: vault.Config{Method: vault.Token, Token: "root", Paths: []string{"app"}, Version: 2},
+			want:   map[string]string{"HOST": "old"},
+			err:    nil,
+		},
+		{
+			name:   "separator and mount",
+			config: vault.Config{Method: vault.Token, Token: "root", Paths: []string{"team/app"}, Mount: "/kv/"},
+			want:   map[string]string{"TOKEN": "t"},
+			err:    nil,
+		},
+		{
+			name: "approle",
+			config: vault.Config{
+				Method: vault.AppRole, RoleID: "role", SecretID: "secret", Paths: []string{"app"}, Separator: ".",
+			},
+			want: map[string]string{"HOST": "db", "DB.PORT": "5432", "DB.USER": "app"},
+			err:  nil,
+		},
+		{
+			name: "kubernetes",
+			config: vault.Config{
+				Method: vault.Kubernetes, Role: "app", JWTFile: jwt, Paths: []string{"team/app"}, Mount: "kv",
+			},
+			want: map[string]string{"TOKEN": "t"},
+			err:  nil,
+		},
+		{
+			name: "kubernetes custom mount",
+			config: vault.Config{
+				Method: vault.Kubernetes, Role: "app", JWTFile: jwt, AuthMount: "k8s", Paths: []string{"override"},
+			},
+			want: map[string]string{"HOST": "override"},
+			err:  nil,
+		},
+		{
+			name: "missing jwt",
+			config: vault.Config{
+				Method: vault.Kubernetes, Role: "app", JWTFile: jwt + ".missing", Paths: []string{"app"},
+			},
+			want: nil,
+			err:  vault.ErrMissingCredentials,
+		},
+		{
+			name:   "login denied",
+			config: vault.Config{Method: vault.AppRole, RoleID: "role", SecretID: "wrong", Paths: []string{"app"}},
+			want:   nil,
+			err:    vault.ErrVaultError,
+		},
+		{
+			name:   "permission denied",
+			config: vault.Config{Method: vault.Token, Token: "wrong", Paths: []string{"app"}},
+			want:   nil,
+			err:    vault.ErrVaultError,
+		},
+		{
+			name:   "not found",
+			config: vault.Config{Method: vault.Token, Token: "root", Paths: []string{"app", "missing"}},
+			want:   nil,
+			err:    vault.ErrSecretNotFound,
+		},
+		{
+			name:   "broken response",
+			config: vault.Config{Method: vault.Token, Token: "root", Paths: []string{"app"}, Namespace: "broken"},
+			want:   nil,
+			err:    vault.ErrVaultError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.Address = srv.URL
+
+			obj, err := vault.NewWithConfig(test.config)
+
+			require.NoError(t, err)
+
+			got, err := obj.Extract(t.Context())
+
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSourceExtractUnavailable(t *testing.T) {
+	t.Parallel()
+
+	obj, err := vault.NewWithConfig(vault.Config{
+		Address: "http://127.0.0.1:1", Paths: []string{"app"}, Method: vault.Token, Token: "t",
+	})
+
+	require.NoError(t, err)
+
+	got, err := obj.Extract(t.Context())
+
+	require.ErrorIs(t, err, vault.ErrVaultError)
+	assert.Nil(t, got)
+}